@@ -0,0 +1,52 @@
+package healthcheck
+
+import "context"
+
+// Aggregator computes the overall availability status from the results of
+// the individual checks.
+type Aggregator interface {
+	Aggregate(details map[string]CheckResult) AvailabilityStatus
+}
+
+// AggregatorFunc is an adapter to allow the use of ordinary functions as
+// Aggregator.
+type AggregatorFunc func(details map[string]CheckResult) AvailabilityStatus
+
+// Aggregate calls f(details).
+func (f AggregatorFunc) Aggregate(details map[string]CheckResult) AvailabilityStatus {
+	return f(details)
+}
+
+// WorstOfAggregator reports the worst status of all checks: the system is
+// down as soon as one check is down. It is the default Aggregator.
+type WorstOfAggregator struct{}
+
+// Aggregate implements Aggregator.
+func (WorstOfAggregator) Aggregate(details map[string]CheckResult) AvailabilityStatus {
+	status := StatusUp
+	for _, check := range details {
+		status = worstStatus(status, check.Status)
+	}
+	return status
+}
+
+// aggregatingChecker replaces the status computed by the wrapped checker
+// with the one computed by its Aggregator.
+type aggregatingChecker struct {
+	engine
+	aggregator Aggregator
+}
+
+func (ck *aggregatingChecker) Check(ctx context.Context) CheckerResult {
+	return aggregate(ck.aggregator, ck.engine.Check(ctx))
+}
+
+// aggregate recomputes the status of result. Results without details are
+// returned unchanged.
+func aggregate(aggregator Aggregator, result CheckerResult) CheckerResult {
+	if aggregator == nil || result.Details == nil {
+		return result
+	}
+	result.Status = aggregator.Aggregate(result.Details)
+	return result
+}
@@ -0,0 +1,38 @@
+// Package azureblob provides health checks for Azure Blob Storage, backed by
+// the github.com/Azure/azure-sdk-for-go/sdk/storage/azblob client. It is a
+// separate module, so the core healthcheck package does not depend on the
+// Azure SDK.
+package azureblob
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob/container"
+)
+
+// ContainerCheck returns a Check that lists at most one blob of the
+// container with client. This verifies that the service is reachable and
+// that the credentials of the client, e.g. a shared key, SAS or Azure AD
+// credential, are valid and grant access to the container. A zero timeout
+// leaves the deadline of the check context.
+func ContainerCheck(client *container.Client, timeout time.Duration) func(ctx context.Context) error {
+	return func(ctx context.Context) error {
+		if timeout > 0 {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, timeout)
+			defer cancel()
+		}
+		if client == nil {
+			return errors.New("client is nil")
+		}
+		pager := client.NewListBlobsFlatPager(&container.ListBlobsFlatOptions{MaxResults: to.Ptr(int32(1))})
+		if _, err := pager.NextPage(ctx); err != nil {
+			return fmt.Errorf("container %s: %w", client.URL(), err)
+		}
+		return nil
+	}
+}
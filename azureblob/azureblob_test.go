@@ -0,0 +1,48 @@
+package azureblob
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob/container"
+)
+
+func TestContainerCheck(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("comp") != "list" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		w.Header().Set("Content-Type", "application/xml")
+		if r.URL.Path != "/account/healthy" {
+			w.Header().Set("x-ms-error-code", "AuthorizationFailure")
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		w.Write([]byte(`<?xml version="1.0" encoding="utf-8"?><EnumerationResults><Blobs/><NextMarker/></EnumerationResults>`))
+	}))
+	defer server.Close()
+
+	for _, test := range []struct {
+		container string
+		healthy   bool
+	}{
+		{"healthy", true},
+		{"forbidden", false},
+	} {
+		client, err := container.NewClientWithNoCredential(server.URL+"/account/"+test.container, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = ContainerCheck(client, time.Second)(context.Background())
+		if test.healthy && err != nil {
+			t.Errorf("%s: %v", test.container, err)
+		}
+		if !test.healthy && err == nil {
+			t.Errorf("%s: no error", test.container)
+		}
+	}
+}
@@ -0,0 +1,121 @@
+package healthcheck
+
+import (
+	"context"
+	"math/rand"
+	"sync"
+	"time"
+)
+
+// EnableBackgroundChecks executes every check in the background every
+// interval instead of on requests, so handlers serve the latest results
+// without waiting for slow checks and probe latency stays predictable.
+// Checks registered with WithPeriodicCheck keep their own schedule. Until a
+// check completed its first execution it is reported as unknown. All
+// handlers and Status share the background executions, which start with the
+// first of them, with the checks registered by then, and run until
+// StopBackgroundChecks.
+func (c *AndictlCheckerConfig) EnableBackgroundChecks(interval time.Duration) {
+	c.backgroundInterval = interval
+}
+
+// StopBackgroundChecks stops the background executions of the checks (see
+// EnableBackgroundChecks). Handlers keep serving the last results.
+func (c *AndictlCheckerConfig) StopBackgroundChecks() {
+	if c.background == nil {
+		return
+	}
+	c.background.mtx.Lock()
+	defer c.background.mtx.Unlock()
+	if c.background.engine != nil {
+		c.background.engine.Stop()
+	}
+}
+
+// backgroundRunner holds the engine executing the checks of a configuration
+// in the background. It is shared by all checkers of the configuration.
+type backgroundRunner struct {
+	mtx    sync.Mutex
+	engine engine
+	causes *causeRecorder
+}
+
+// backgroundChecker returns the checker serving the results of the shared
+// background engine, or nil if background checks are disabled.
+func (c AndictlCheckerConfig) backgroundChecker(aggregator Aggregator) engine {
+	if c.backgroundInterval <= 0 || c.background == nil {
+		return nil
+	}
+	runner := c.background
+	runner.mtx.Lock()
+	defer runner.mtx.Unlock()
+	if runner.engine == nil {
+		all := c
+		all.include = nil
+		runner.engine = all.newBackendChecker(aggregator, []interceptor{runner.causes.intercept})
+	}
+	return &includingChecker{engine: runner.engine, include: c.include}
+}
+
+// causeRecorder returns the recorder of the causes of the checks executed by
+// the checkers of c: a new one, or the one of the shared background engine.
+func (c AndictlCheckerConfig) causeRecorder() *causeRecorder {
+	if c.backgroundInterval > 0 && c.background != nil {
+		return c.background.causes
+	}
+	return newCauseRecorder()
+}
+
+// includingChecker serves the results of a shared engine for the checks
+// selected by include, or all checks if it is nil. Starting and stopping it
+// has no effect on the shared engine.
+type includingChecker struct {
+	engine  engine
+	include func(name string) bool
+}
+
+func (ck *includingChecker) Start() {}
+
+func (ck *includingChecker) Stop() {}
+
+func (ck *includingChecker) Check(ctx context.Context) CheckerResult {
+	result := ck.engine.Check(ctx)
+	if ck.include == nil || result.Details == nil {
+		return result
+	}
+	details := make(map[string]CheckResult, len(result.Details))
+	for name, check := range result.Details {
+		if ck.include(name) {
+			details[name] = check
+		}
+	}
+	result.Details = details
+	return result
+}
+
+// SetScheduleJitter spreads the executions of background and periodic checks
+// over time, so that many replicas do not hit a shared dependency at the same
+// instant. Each check gets a random extra initial delay and a random extra
+// interval between zero and jitter, chosen when a handler is created.
+func (c *AndictlCheckerConfig) SetScheduleJitter(jitter time.Duration) {
+	c.scheduleJitter = jitter
+}
+
+// schedule makes checks without a refresh period periodic if background
+// checks are enabled, and applies the jitter to all periodic checks.
+func (c AndictlCheckerConfig) schedule(checks []scheduledCheck) {
+	var random *rand.Rand
+	if c.scheduleJitter > 0 {
+		// The default source is not seeded, replicas must not share it.
+		random = rand.New(rand.NewSource(time.Now().UnixNano()))
+	}
+	for i := range checks {
+		if checks[i].refreshPeriod == 0 && c.backgroundInterval > 0 {
+			checks[i].refreshPeriod = c.backgroundInterval
+		}
+		if checks[i].refreshPeriod > 0 && random != nil {
+			checks[i].initialDelay += time.Duration(random.Int63n(int64(c.scheduleJitter)))
+			checks[i].refreshPeriod += time.Duration(random.Int63n(int64(c.scheduleJitter)))
+		}
+	}
+}
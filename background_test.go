@@ -0,0 +1,64 @@
+package healthcheck
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestBackgroundChecksIncludeCriticalChecks(t *testing.T) {
+	for _, criticalFirst := range []bool{false, true} {
+		var executions int32
+		config := InitChecker()
+		config.MustAdd(WithCacheDuration(0))
+		if err := config.AddCriticalCheck(Check{Name: "database", Check: func(ctx context.Context) error {
+			atomic.AddInt32(&executions, 1)
+			return nil
+		}}); err != nil {
+			t.Fatal(err)
+		}
+		config.SetCriticalFirst(criticalFirst)
+		config.EnableBackgroundChecks(time.Hour)
+		handler := config.GetCheckerHandler()
+		defer config.StopBackgroundChecks()
+		for i := 0; i < 5; i++ {
+			handler(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/health", nil))
+		}
+		if n := atomic.LoadInt32(&executions); n > 1 {
+			t.Errorf("critical first %v: critical check executed %d times, want at most once", criticalFirst, n)
+		}
+	}
+}
+
+func TestBackgroundChecksAreShared(t *testing.T) {
+	executed := make(chan struct{}, 10)
+	config := InitChecker()
+	config.MustAdd(WithCheck(Check{Name: "database", Check: func(ctx context.Context) error {
+		executed <- struct{}{}
+		return nil
+	}}))
+	config.EnableBackgroundChecks(time.Hour)
+	handlers := []http.HandlerFunc{
+		config.GetCheckerHandler(),
+		config.GetLivenessHandler(),
+		config.GetReadinessHandler(),
+		config.GetTaggedHandler("db"),
+	}
+	<-executed
+	for _, handler := range handlers {
+		handler(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/health", nil))
+	}
+	config.StopBackgroundChecks()
+	if n := len(executed); n != 0 {
+		t.Errorf("check executed %d more times, want once in total", n+1)
+	}
+
+	rec := httptest.NewRecorder()
+	handlers[2](rec, httptest.NewRequest(http.MethodGet, "/ready", nil))
+	if rec.Code != http.StatusOK {
+		t.Errorf("readiness status code %d after StopBackgroundChecks, want %d", rec.Code, http.StatusOK)
+	}
+}
@@ -0,0 +1,83 @@
+package healthcheck
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/tls"
+	"encoding/hex"
+	"fmt"
+	"time"
+)
+
+// BrokerRoundTripCheck returns a Check that publishes a canary message with a
+// unique correlation ID and waits until it is consumed back within the
+// timeout, validating the full produce-consume path of a message broker.
+// publish must send the correlation ID to the canary topic or queue; receive
+// must return the correlation ID of the next message consumed from it. The
+// consumer must already be subscribed when publish is called. Messages with
+// other correlation IDs (e.g., left over from previous executions) are
+// skipped. This adapts any client library, such as a Kafka, RabbitMQ or NATS
+// client, see NATSRoundTripCheck for a built-in NATS variant.
+func BrokerRoundTripCheck(
+	publish func(ctx context.Context, correlationID string) error,
+	receive func(ctx context.Context) (string, error),
+	timeout time.Duration,
+) func(ctx context.Context) error {
+	return func(ctx context.Context) error {
+		ctx, cancel := withOptionalTimeout(ctx, timeout)
+		defer cancel()
+		id, err := newCorrelationID()
+		if err != nil {
+			return err
+		}
+		if err := publish(ctx, id); err != nil {
+			return fmt.Errorf("publish failed: %w", err)
+		}
+		for {
+			received, err := receive(ctx)
+			if err != nil {
+				return fmt.Errorf("canary %s not received: %w", id, err)
+			}
+			if received == id {
+				return nil
+			}
+		}
+	}
+}
+
+// NATSRoundTripCheck returns a Check that connects to the NATS server at url,
+// subscribes to subject, publishes a canary message on it and waits for its
+// delivery within the timeout. A nil config only uses TLS when the URL scheme
+// is "tls" or the server requires it.
+func NATSRoundTripCheck(url, subject string, config *tls.Config, timeout time.Duration) func(ctx context.Context) error {
+	return func(ctx context.Context) error {
+		ctx, cancel := withOptionalTimeout(ctx, timeout)
+		defer cancel()
+		conn, err := dialNATS(ctx, url, config)
+		if err != nil {
+			return err
+		}
+		defer conn.Close()
+		if err := conn.subscribe(subject, "1"); err != nil {
+			return err
+		}
+		return BrokerRoundTripCheck(
+			func(ctx context.Context, correlationID string) error {
+				return conn.publish(subject, "", []byte(correlationID))
+			},
+			func(ctx context.Context) (string, error) {
+				_, payload, err := conn.nextMsg()
+				return string(payload), err
+			},
+			timeout,
+		)(ctx)
+	}
+}
+
+func newCorrelationID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
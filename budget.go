@@ -0,0 +1,70 @@
+package healthcheck
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// Priority decides how a check is treated when the evaluation budget (see
+// SetEvaluationBudget) runs short.
+type Priority int
+
+const (
+	// PriorityNormal checks may use the whole remaining evaluation budget.
+	PriorityNormal Priority = iota
+	// PriorityLow checks may only use half of the remaining evaluation
+	// budget, so they are cut off before the normal priority checks.
+	PriorityLow
+)
+
+// SetEvaluationBudget limits the total duration of one evaluation of all
+// checks. Checks that have not completed when the budget is exhausted fail,
+// so the handler always responds within the budget (e.g., the kubelet probe
+// timeout) no matter how many checks are registered. With the critical-first
+// strategy, the remaining checks share what the critical checks left over.
+func (c *AndictlCheckerConfig) SetEvaluationBudget(budget time.Duration) {
+	c.budget = budget
+}
+
+// SetCheckPriority sets the priority of the named check. Checks without an
+// explicit priority have PriorityNormal.
+func (c *AndictlCheckerConfig) SetCheckPriority(name string, priority Priority) {
+	if c.priorities == nil {
+		c.priorities = map[string]Priority{}
+	}
+	c.priorities[name] = priority
+}
+
+// budgetChecker bounds every evaluation of the wrapped checker to budget.
+type budgetChecker struct {
+	engine
+	budget time.Duration
+}
+
+func (ck *budgetChecker) Check(ctx context.Context) CheckerResult {
+	ctx, cancel := context.WithTimeout(ctx, ck.budget)
+	defer cancel()
+	return ck.engine.Check(ctx)
+}
+
+// priorityInterceptor returns an interceptor that shortens the deadline
+// of low priority checks to half of the remaining evaluation budget.
+func priorityInterceptor(priorities map[string]Priority) interceptor {
+	return func(next interceptorFunc) interceptorFunc {
+		return func(ctx context.Context, name string, state checkState) checkState {
+			deadline, ok := ctx.Deadline()
+			if !ok || priorities[name] != PriorityLow {
+				return next(ctx, name, state)
+			}
+			parent := ctx
+			ctx, cancel := context.WithTimeout(ctx, time.Until(deadline)/2)
+			defer cancel()
+			state = next(ctx, name, state)
+			if state.Result != nil && ctx.Err() != nil && parent.Err() == nil {
+				state.Result = fmt.Errorf("low priority check cut off by evaluation budget: %w", state.Result)
+			}
+			return state
+		}
+	}
+}
@@ -0,0 +1,64 @@
+package healthcheck
+
+import (
+	"context"
+	"fmt"
+)
+
+// Bulkhead isolates the execution of checks. Every check wrapped by the same
+// Bulkhead runs in its own worker goroutine and needs one of a limited number
+// of slots to execute; further executions wait in a bounded queue and fail
+// immediately once the queue is full. A check that does not return in time
+// keeps its slot until it eventually completes, so one slow dependency can
+// never occupy more than its slots and cannot starve the other checks. Wrap
+// several checks with the same Bulkhead to isolate them as a group.
+type Bulkhead struct {
+	slots chan struct{}
+	queue chan struct{}
+}
+
+// NewBulkhead creates a Bulkhead with the given number of concurrent
+// executions and queued executions.
+func NewBulkhead(concurrency, queue int) *Bulkhead {
+	if concurrency < 1 {
+		concurrency = 1
+	}
+	if queue < 0 {
+		queue = 0
+	}
+	return &Bulkhead{
+		slots: make(chan struct{}, concurrency),
+		queue: make(chan struct{}, concurrency+queue),
+	}
+}
+
+// Wrap returns a Check that executes check within the Bulkhead.
+func (b *Bulkhead) Wrap(check func(ctx context.Context) error) func(ctx context.Context) error {
+	return func(ctx context.Context) error {
+		select {
+		case b.queue <- struct{}{}:
+		default:
+			return fmt.Errorf("bulkhead full: %d executions pending", cap(b.queue))
+		}
+		select {
+		case b.slots <- struct{}{}:
+		case <-ctx.Done():
+			<-b.queue
+			return fmt.Errorf("no bulkhead slot available: %w", ctx.Err())
+		}
+		result := make(chan error, 1)
+		go func() {
+			defer func() {
+				<-b.slots
+				<-b.queue
+			}()
+			result <- check(ctx)
+		}()
+		select {
+		case err := <-result:
+			return err
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
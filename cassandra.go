@@ -0,0 +1,29 @@
+package healthcheck
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/gocql/gocql"
+)
+
+// CassandraSession is the part of *gocql.Session used by CassandraCheck.
+type CassandraSession interface {
+	Query(stmt string, values ...interface{}) *gocql.Query
+}
+
+// CassandraCheck returns a Check that executes the lightweight query
+// "SELECT release_version FROM system.local" through session, against a
+// Cassandra or ScyllaDB cluster.
+func CassandraCheck(session CassandraSession, timeout time.Duration) func(ctx context.Context) error {
+	return func(ctx context.Context) error {
+		ctx, cancel := withOptionalTimeout(ctx, timeout)
+		defer cancel()
+		if session == nil {
+			return fmt.Errorf("session is nil")
+		}
+		var version string
+		return session.Query("SELECT release_version FROM system.local").WithContext(ctx).Scan(&version)
+	}
+}
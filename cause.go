@@ -0,0 +1,158 @@
+package healthcheck
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// CauseError is returned by checks composed of other checks, such as
+// composite or federated checks. It records which nested component failed
+// and through which components the failure propagated, so the checker
+// handler can report the root cause as a tree rather than a flat message.
+type CauseError struct {
+	// Name of the failed component.
+	Name string
+	// Err is the error of a leaf component. It is nil for components that
+	// failed because of their Causes.
+	Err error
+	// Causes are the failed nested components.
+	Causes []*CauseError
+}
+
+func (e *CauseError) Error() string {
+	if e.Err != nil {
+		return e.Name + ": " + e.Err.Error()
+	}
+	causes := make([]string, len(e.Causes))
+	for i, cause := range e.Causes {
+		causes[i] = cause.Error()
+	}
+	return e.Name + ": " + strings.Join(causes, "; ")
+}
+
+func (e *CauseError) Unwrap() error { return e.Err }
+
+// Cause is a node of the cause tree written into the response of the checker
+// handler.
+type Cause struct {
+	Name   string  `json:"name"`
+	Error  string  `json:"error,omitempty"`
+	Causes []Cause `json:"causes,omitempty"`
+}
+
+func causeTree(err *CauseError) Cause {
+	cause := Cause{Name: err.Name}
+	if err.Err != nil {
+		cause.Error = err.Err.Error()
+	}
+	for _, nested := range err.Causes {
+		cause.Causes = append(cause.Causes, causeTree(nested))
+	}
+	return cause
+}
+
+// causeRecorder keeps the cause tree of every check that failed with a
+// CauseError.
+type causeRecorder struct {
+	mtx    sync.Mutex
+	causes map[string][]Cause
+}
+
+func newCauseRecorder() *causeRecorder {
+	return &causeRecorder{causes: map[string][]Cause{}}
+}
+
+// intercept is an interceptor recording the cause tree of every check.
+func (cr *causeRecorder) intercept(next interceptorFunc) interceptorFunc {
+	return func(ctx context.Context, name string, state checkState) checkState {
+		state = next(ctx, name, state)
+		var err *CauseError
+		cr.mtx.Lock()
+		defer cr.mtx.Unlock()
+		if errors.As(state.Result, &err) {
+			cr.causes[name] = causeTree(err).Causes
+		} else {
+			delete(cr.causes, name)
+		}
+		return state
+	}
+}
+
+func (cr *causeRecorder) get(name string) []Cause {
+	cr.mtx.Lock()
+	defer cr.mtx.Unlock()
+	return cr.causes[name]
+}
+
+// FederatedCheck returns a Check that queries the health endpoint of another
+// service exposing this package's response format, and fails if that
+// service is down. The failed checks of the remote service, including their
+// own cause trees, are reported as causes (see CauseError).
+func FederatedCheck(url string, timeout time.Duration) func(ctx context.Context) error {
+	client := http.Client{Timeout: timeout}
+	return func(ctx context.Context) error {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+		if err != nil {
+			return err
+		}
+		resp, err := client.Do(req)
+		if err != nil {
+			return err
+		}
+		defer resp.Body.Close()
+		var body struct {
+			Status  AvailabilityStatus `json:"status"`
+			Details map[string]struct {
+				Status AvailabilityStatus `json:"status"`
+				Error  string             `json:"error"`
+				Causes []Cause            `json:"causes"`
+			} `json:"details"`
+		}
+		if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+			return fmt.Errorf("invalid response (status %d): %w", resp.StatusCode, err)
+		}
+		if body.Status != StatusDown && resp.StatusCode == http.StatusOK {
+			return nil
+		}
+		failure := &CauseError{Name: url}
+		names := make([]string, 0, len(body.Details))
+		for name := range body.Details {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			check := body.Details[name]
+			if check.Status != StatusDown {
+				continue
+			}
+			cause := &CauseError{Name: name, Causes: causeErrors(check.Causes)}
+			if len(cause.Causes) == 0 {
+				cause.Err = errors.New(check.Error)
+			}
+			failure.Causes = append(failure.Causes, cause)
+		}
+		if len(failure.Causes) == 0 {
+			failure.Err = fmt.Errorf("returned status %d", resp.StatusCode)
+		}
+		return failure
+	}
+}
+
+func causeErrors(causes []Cause) []*CauseError {
+	var errs []*CauseError
+	for _, cause := range causes {
+		err := &CauseError{Name: cause.Name, Causes: causeErrors(cause.Causes)}
+		if cause.Error != "" {
+			err.Err = errors.New(cause.Error)
+		}
+		errs = append(errs, err)
+	}
+	return errs
+}
@@ -0,0 +1,118 @@
+package healthcheck
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// cgroupRoot is the mount point of the cgroup filesystems.
+const cgroupRoot = "/sys/fs/cgroup"
+
+// CgroupMemoryCheck returns a Check that fails if the memory usage of the
+// cgroup of the process (i.e., of its container) exceeds maxRatio of the
+// cgroup memory limit. Like the kubelet, usage is the working set, which
+// excludes inactive file cache the kernel reclaims before invoking the OOM
+// killer. Both cgroup v1 and v2 are supported; the check always passes when
+// the cgroup has no memory limit.
+func CgroupMemoryCheck(maxRatio float64) func(ctx context.Context) error {
+	return func(ctx context.Context) error {
+		usage, limit, err := cgroupMemory()
+		if err != nil {
+			return err
+		}
+		if limit == 0 {
+			return nil
+		}
+		ratio := float64(usage) / float64(limit)
+		if ratio > maxRatio {
+			return fmt.Errorf("cgroup memory usage %d bytes is above %.0f%% of the limit %d bytes", usage, maxRatio*100, limit)
+		}
+		return nil
+	}
+}
+
+// cgroupMemory returns the working set and the memory limit of the cgroup of
+// the process. A limit of 0 means unlimited.
+func cgroupMemory() (uint64, uint64, error) {
+	paths, err := cgroupPaths()
+	if err != nil {
+		return 0, 0, err
+	}
+	// Without cgroup namespace, the cgroup of a container is mounted as root.
+	dir := func(root, path, file string) string {
+		if _, err := os.Stat(filepath.Join(root, path, file)); err == nil {
+			return filepath.Join(root, path)
+		}
+		return root
+	}
+	usageFile, limitFile, inactiveField := "memory.current", "memory.max", "inactive_file"
+	var memoryDir string
+	if _, err := os.Stat(filepath.Join(cgroupRoot, "cgroup.controllers")); err == nil {
+		memoryDir = dir(cgroupRoot, paths[""], limitFile)
+	} else {
+		usageFile, limitFile, inactiveField = "memory.usage_in_bytes", "memory.limit_in_bytes", "total_inactive_file"
+		memoryDir = dir(filepath.Join(cgroupRoot, "memory"), paths["memory"], limitFile)
+	}
+	limit, err := readCgroupValue(filepath.Join(memoryDir, limitFile))
+	if err != nil {
+		return 0, 0, err
+	}
+	// cgroup v1 reports no limit as a huge page aligned value.
+	if limit >= 1<<62 {
+		limit = 0
+	}
+	usage, err := readCgroupValue(filepath.Join(memoryDir, usageFile))
+	if err != nil {
+		return 0, 0, err
+	}
+	stat, err := readProcFields(filepath.Join(memoryDir, "memory.stat"))
+	if err != nil {
+		return 0, 0, err
+	}
+	if inactive := stat[inactiveField]; inactive < usage {
+		usage -= inactive
+	}
+	return usage, limit, nil
+}
+
+// cgroupPaths maps the controllers of /proc/self/cgroup to the cgroup of the
+// process. The cgroup v2 hierarchy has no controller name.
+func cgroupPaths() (map[string]string, error) {
+	f, err := os.Open("/proc/self/cgroup")
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	paths := map[string]string{}
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		// Lines have the format "hierarchy-ID:controller-list:cgroup-path".
+		parts := strings.SplitN(scanner.Text(), ":", 3)
+		if len(parts) != 3 {
+			continue
+		}
+		for _, controller := range strings.Split(parts[1], ",") {
+			paths[controller] = parts[2]
+		}
+	}
+	return paths, scanner.Err()
+}
+
+// readCgroupValue reads a cgroup file holding a single number, or "max" for
+// no limit, which is returned as 0.
+func readCgroupValue(path string) (uint64, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return 0, err
+	}
+	value := strings.TrimSpace(string(content))
+	if value == "max" {
+		return 0, nil
+	}
+	return strconv.ParseUint(value, 10, 64)
+}
@@ -0,0 +1,14 @@
+//go:build !linux
+
+package healthcheck
+
+import "context"
+
+// CgroupMemoryCheck returns a Check that fails if the memory usage of the
+// cgroup of the process exceeds maxRatio of the cgroup memory limit. It is
+// only supported on Linux.
+func CgroupMemoryCheck(maxRatio float64) func(ctx context.Context) error {
+	return func(ctx context.Context) error {
+		return errUnsupportedPlatform
+	}
+}
@@ -7,55 +7,373 @@ import (
 	"log"
 	"net/http"
 	"time"
+)
 
-	"github.com/alexliesenfeld/health"
+const (
+	defaultCacheDuration = 1 * time.Second
+	defaultTimeout       = 10 * time.Second
 )
 
 type AndictlCheckerConfig struct {
-	checkers []health.CheckerOption
+	checkers           []Option
+	critical           []Option
+	criticalFirst      bool
+	skipOnDown         bool
+	aggregator         Aggregator
+	weights            map[string]float64
+	trendWindow        time.Duration
+	latency            *LatencyAnomalyOptions
+	budget             time.Duration
+	priorities         map[string]Priority
+	severities         map[string]Severity
+	responseTTL        time.Duration
+	snapshots          *snapshotRecorder
+	probes             map[string][]Probe
+	tags               map[string][]string
+	include            func(name string) bool // selects the checks of a probe
+	createdAt          time.Time
+	maintenance        *maintenanceState
+	override           *overrideState
+	dependencies       map[string][]string
+	backgroundInterval time.Duration
+	scheduleJitter     time.Duration
+	background         *backgroundRunner
 }
 
 func InitChecker() AndictlCheckerConfig {
 	config := AndictlCheckerConfig{}
-	config.checkers = make([]health.CheckerOption, 0, 10)
+	config.checkers = make([]Option, 0, 10)
+	config.snapshots = newSnapshotRecorder()
+	config.createdAt = time.Now()
+	config.maintenance = newMaintenanceState()
+	config.override = &overrideState{}
+	config.background = &backgroundRunner{causes: newCauseRecorder()}
 	// Set the time-to-live for our cache to 1 second (default).
-	config.AddCheck(health.WithCacheDuration(1 * time.Second))
+	config.AddCheck(WithCacheDuration(defaultCacheDuration))
 	// Configure a global timeout that will be applied to all checks.
-	config.AddCheck(health.WithTimeout(10 * time.Second))
+	config.AddCheck(WithTimeout(defaultTimeout))
 	// A check configuration to see if our database connection is up.
 	// The check function will be executed for each HTTP request.
 	// Set a status listener that will be invoked when the health status changes.
 	// More powerful hooks are also available (see docs).
-	config.AddCheck(health.WithStatusListener(func(ctx context.Context, state health.CheckerState) {
-		log.Println(fmt.Sprintf("health status changed to %s", state.Status))
+	config.AddCheck(WithStatusListener(func(ctx context.Context, status AvailabilityStatus) {
+		log.Println(fmt.Sprintf("health status changed to %s", status))
 	}))
 	return config
 }
 
-func (c *AndictlCheckerConfig) AddGoroutineCountCheck(threshold int) {
-	check := health.WithCheck(health.Check{
-		Name:    "goroutine-threshold", // A unique check name.
-		Timeout: 2 * time.Second,       // A check specific timeout.
+// AddGoroutineCountCheck registers a GoroutineCountCheck named
+// "goroutine-threshold", customized by options.
+func (c *AndictlCheckerConfig) AddGoroutineCountCheck(threshold int, options ...CheckOption) error {
+	settings := newHelperCheck("goroutine-threshold", 1*time.Second, options)
+	check := WithCheck(Check{
+		Name:    settings.name,    // A unique check name.
+		Timeout: settings.timeout, // A check specific timeout.
 		Check:   GoroutineCountCheck(threshold),
 	})
 	fmt.Println("Check GoroutineCountCheck threshold: ", threshold)
-	c.AddCheck(check)
+	return c.AddCheck(check)
 }
 
-func (c *AndictlCheckerConfig) AddCheck(check health.CheckerOption) {
+// AddCheck adds an option to the configuration. It returns an error and
+// leaves the configuration unchanged if the option is invalid, e.g., if it
+// registers a check whose name is already taken (see Validate).
+func (c *AndictlCheckerConfig) AddCheck(check Option) error {
+	if check == nil {
+		return invalidConfig("nil option")
+	}
+	if _, err := c.engineConfig(check); err != nil {
+		return err
+	}
 	c.checkers = append(c.checkers, check)
+	return nil
 }
 
-func (c *AndictlCheckerConfig) AddDatabaseCheck(db *sql.DB) {
-	check := health.WithCheck(health.Check{
-		Name:    "database",      // A unique check name.
-		Timeout: 2 * time.Second, // A check specific timeout.
-		Check:   DatabasePingCheck(db, 1*time.Second),
+// AddDatabaseCheck registers a DatabasePingCheck named "database" with a
+// ping timeout of 1 second, customized by options.
+func (c *AndictlCheckerConfig) AddDatabaseCheck(db *sql.DB, options ...CheckOption) error {
+	if db == nil {
+		return invalidConfig("nil database")
+	}
+	settings := newHelperCheck("database", 1*time.Second, options)
+	check := WithCheck(Check{
+		Name:    settings.name,    // A unique check name.
+		Timeout: settings.timeout, // A check specific timeout.
+		Check:   DatabasePingCheck(db, settings.pingTimeout),
 	})
 	fmt.Println("Check database health")
-	c.AddCheck(check)
+	return c.AddCheck(check)
+}
+
+// AddPostgresReplicaCheck registers a PostgresReplicationLagCheck named
+// "postgres-replica" that fails if the replica lags by more than maxLag,
+// customized by options.
+func (c *AndictlCheckerConfig) AddPostgresReplicaCheck(db *sql.DB, maxLag time.Duration, options ...CheckOption) error {
+	if db == nil {
+		return invalidConfig("nil database")
+	}
+	settings := newHelperCheck("postgres-replica", 1*time.Second, options)
+	check := WithCheck(Check{
+		Name:    settings.name,    // A unique check name.
+		Timeout: settings.timeout, // A check specific timeout.
+		Check:   PostgresReplicationLagCheck(db, maxLag, settings.pingTimeout),
+	})
+	fmt.Println("Check postgres replica health")
+	return c.AddCheck(check)
+}
+
+// AddRedisCheck registers a RedisPingCheck named "redis", customized by
+// checkOptions. A zero options.Timeout defaults to 1 second.
+func (c *AndictlCheckerConfig) AddRedisCheck(options RedisOptions, checkOptions ...CheckOption) error {
+	if options.Timeout == 0 {
+		options.Timeout = 1 * time.Second
+	}
+	settings := newHelperCheck("redis", options.Timeout, checkOptions)
+	options.Timeout = settings.pingTimeout
+	check := WithCheck(Check{
+		Name:    settings.name,    // A unique check name.
+		Timeout: settings.timeout, // A check specific timeout.
+		Check:   RedisPingCheck(options),
+	})
+	fmt.Println("Check redis health")
+	return c.AddCheck(check)
+}
+
+// AddMongoCheck registers a check named "mongodb" that calls ping with a
+// timeout of 2 seconds, customized by options. ping usually pings a
+// *mongo.Client:
+//
+//	func(ctx context.Context) error { return client.Ping(ctx, readpref.Primary()) }
+func (c *AndictlCheckerConfig) AddMongoCheck(ping func(ctx context.Context) error, options ...CheckOption) error {
+	if ping == nil {
+		return invalidConfig("nil ping function")
+	}
+	settings := newHelperCheck("mongodb", 2*time.Second, options)
+	check := WithCheck(Check{
+		Name:    settings.name,    // A unique check name.
+		Timeout: settings.timeout, // A check specific timeout.
+		Check: MongoPingCheck(func(ctx context.Context, _ struct{}) error {
+			return ping(ctx)
+		}, struct{}{}, settings.pingTimeout),
+	})
+	fmt.Println("Check mongodb health")
+	return c.AddCheck(check)
+}
+
+// AddCassandraCheck registers a CassandraCheck named "cassandra" with a
+// query timeout of 2 seconds, customized by options.
+func (c *AndictlCheckerConfig) AddCassandraCheck(session CassandraSession, options ...CheckOption) error {
+	if session == nil {
+		return invalidConfig("nil session")
+	}
+	settings := newHelperCheck("cassandra", 2*time.Second, options)
+	check := WithCheck(Check{
+		Name:    settings.name,    // A unique check name.
+		Timeout: settings.timeout, // A check specific timeout.
+		Check:   CassandraCheck(session, settings.pingTimeout),
+	})
+	fmt.Println("Check cassandra health")
+	return c.AddCheck(check)
+}
+
+// AddClickHouseCheck registers a ClickHouseCheck named "clickhouse",
+// customized by checkOptions. A zero options.Timeout defaults to 2 seconds.
+func (c *AndictlCheckerConfig) AddClickHouseCheck(options ClickHouseOptions, checkOptions ...CheckOption) error {
+	if options.Timeout == 0 {
+		options.Timeout = 2 * time.Second
+	}
+	settings := newHelperCheck("clickhouse", options.Timeout, checkOptions)
+	options.Timeout = settings.pingTimeout
+	check := WithCheck(Check{
+		Name:    settings.name,    // A unique check name.
+		Timeout: settings.timeout, // A check specific timeout.
+		Check:   ClickHouseCheck(options),
+	})
+	fmt.Println("Check clickhouse health")
+	return c.AddCheck(check)
+}
+
+// AddCriticalCheck registers a check the service cannot work without. With the
+// critical-first strategy (see SetCriticalFirst) critical checks are evaluated
+// before all other checks; otherwise they behave like any other check.
+func (c *AndictlCheckerConfig) AddCriticalCheck(check Check) error {
+	option := WithCheck(check)
+	if _, err := c.engineConfig(option); err != nil {
+		return err
+	}
+	c.critical = append(c.critical, option)
+	return nil
+}
+
+// SetCriticalFirst enables the critical-first execution strategy. Critical
+// checks are evaluated first and, if skipOnDown is set, the remaining checks
+// are skipped as long as the critical checks report the system as down. This
+// keeps evaluation fast and spares the other dependencies during an outage.
+func (c *AndictlCheckerConfig) SetCriticalFirst(skipOnDown bool) {
+	c.criticalFirst = true
+	c.skipOnDown = skipOnDown
+}
+
+// SetAggregator replaces the rule used to compute the overall status from the
+// individual check results. The default is WorstOfAggregator.
+func (c *AndictlCheckerConfig) SetAggregator(aggregator Aggregator) {
+	c.aggregator = aggregator
+}
+
+// EnableTrends adds a trend field (see Trend) to the response of the checker
+// handler, for every check and for the overall status. A status transition
+// shapes the trend for the duration of window, after which the trend is
+// stable again.
+func (c *AndictlCheckerConfig) EnableTrends(window time.Duration) {
+	c.trendWindow = window
 }
 
 func (c AndictlCheckerConfig) GetCheckerHandler() http.HandlerFunc {
-	return health.NewHandler(health.NewChecker(c.checkers...))
+	writer := &resultWriter{causes: c.causeRecorder()}
+	checker := c.newChecker(writer.causes.intercept)
+	if c.trendWindow > 0 {
+		writer.trends = newTrendTracker(c.trendWindow)
+		checker = &trendChecker{engine: checker, tracker: writer.trends}
+	}
+	if c.override != nil {
+		writer.override = c.override
+		checker = &overrideChecker{engine: checker, state: c.override}
+	}
+	var cached http.HandlerFunc
+	if c.responseTTL > 0 {
+		cached = (&cachingHandler{checker: checker, writer: writer, ttl: c.responseTTL}).ServeHTTP
+	}
+	return func(w http.ResponseWriter, r *http.Request) {
+		// Parsing the query allocates, cached responses must not.
+		var tags string
+		if r.URL.RawQuery != "" {
+			tags = r.URL.Query().Get("tags")
+		}
+		if cached != nil && tags == "" {
+			cached(w, r)
+			return
+		}
+		result := checker.Check(r.Context())
+		if tags != "" {
+			// The filter aggregates the status again, statuses forced for the
+			// whole system apply to its result.
+			result = c.filterByTags(result, tags)
+			c.maintenance.apply(&result)
+			c.override.apply(&result)
+		}
+		w.Header().Set("Cache-Control", "no-cache")
+		w.Header().Set("Pragma", "no-cache")
+		w.Header().Set("Expires", "-1")
+		writer.Write(&result, statusCode(result.Status), w, r)
+	}
+}
+
+// EnableCachedResponses makes the checker handler serve a pre-serialized
+// response that is refreshed at most once per ttl. Serving a cached response
+// takes no locks and almost no allocations, which suits endpoints probed many
+// times per second. Responses may be stale by up to ttl plus the duration of
+// one evaluation.
+func (c *AndictlCheckerConfig) EnableCachedResponses(ttl time.Duration) {
+	c.responseTTL = ttl
+}
+
+// newChecker builds the checker of all registered checks. interceptors are
+// appended to the interceptors installed by the configuration, unless the
+// checks run in the background (see backgroundChecker).
+func (c AndictlCheckerConfig) newChecker(interceptors ...interceptor) engine {
+	aggregator := c.aggregator
+	if aggregator == nil {
+		aggregator = WorstOfAggregator{}
+	}
+	backend := c.backgroundChecker(aggregator)
+	if backend == nil {
+		backend = c.newBackendChecker(aggregator, interceptors)
+	}
+	var checker engine = &aggregatingChecker{engine: backend, aggregator: aggregator}
+	if c.maintenance != nil {
+		checker = &maintenanceChecker{engine: checker, state: c.maintenance}
+	}
+	if c.budget > 0 {
+		checker = &budgetChecker{engine: checker, budget: c.budget}
+	}
+	if c.snapshots != nil {
+		checker = &snapshotChecker{engine: checker, recorder: c.snapshots}
+	}
+	return checker
+}
+
+func (c AndictlCheckerConfig) newBackendChecker(aggregator Aggregator, extra []interceptor) engine {
+	// Interceptors are stateful, each checker gets its own instances.
+	chain := []interceptor{degradedInterceptor}
+	if len(c.severities) > 0 {
+		chain = append(chain, severityInterceptor(c.severities))
+	}
+	if len(c.priorities) > 0 {
+		chain = append(chain, priorityInterceptor(c.priorities))
+	}
+	if c.latency != nil {
+		chain = append(chain, newLatencyAnomalyDetector(*c.latency).intercept)
+	}
+	chain = append(chain, extra...)
+	cfg := newEngineConfig(c.checkers...)
+	// Critical checks share the global options, e.g., timeouts and
+	// listeners, but not the checks of cfg.
+	critical := newEngineConfig(c.checkers...)
+	critical.checks = nil
+	for _, option := range c.critical {
+		option(&critical)
+	}
+	if recovery := newRecoveryTracker(cfg.checks, critical.checks); recovery != nil {
+		// Recovery applies to the status set by the other interceptors.
+		chain = append([]interceptor{recovery.intercept}, chain...)
+	}
+	if cfg.startupGracePeriod > 0 {
+		// The grace period applies to the final status of every check.
+		chain = append([]interceptor{startupGraceInterceptor(c.createdAt.Add(cfg.startupGracePeriod))}, chain...)
+	}
+	if c.maintenance != nil {
+		// Checks in maintenance are not executed at all.
+		chain = append([]interceptor{c.maintenance.intercept}, chain...)
+	}
+	var dependencies *dependencyTracker
+	if len(c.dependencies) > 0 {
+		// Dependencies see the final status of every check.
+		dependencies = newDependencyTracker(c.dependencies)
+		chain = append([]interceptor{dependencies.intercept}, chain...)
+	}
+	cfg.interceptors = chain
+	if !c.criticalFirst || len(c.critical) == 0 {
+		for _, option := range c.critical {
+			option(&cfg)
+		}
+		c.schedule(cfg.checks)
+		cfg.checks = c.includedChecks(cfg.checks)
+		return dependencies.wrap(newHealthEngine(cfg), cfg)
+	}
+	c.schedule(cfg.checks)
+	c.schedule(critical.checks)
+	cfg.checks = c.includedChecks(cfg.checks)
+	critical.checks = c.includedChecks(critical.checks)
+	critical.interceptors = chain
+	return &criticalFirstChecker{
+		critical:   dependencies.wrap(newHealthEngine(critical), critical),
+		rest:       dependencies.wrap(newHealthEngine(cfg), cfg),
+		skipOnDown: c.skipOnDown,
+		aggregator: aggregator,
+	}
+}
+
+// includedChecks returns the checks selected by c.include, or all checks if
+// it is nil.
+func (c AndictlCheckerConfig) includedChecks(checks []scheduledCheck) []scheduledCheck {
+	if c.include == nil {
+		return checks
+	}
+	included := make([]scheduledCheck, 0, len(checks))
+	for _, check := range checks {
+		if c.include(check.Name) {
+			included = append(included, check)
+		}
+	}
+	return included
 }
@@ -0,0 +1,372 @@
+package healthcheck
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// ClickHouseOptions configures a ClickHouseCheck.
+type ClickHouseOptions struct {
+	// Addr is the host:port of the native protocol (e.g., "localhost:9000")
+	// or the URL of the HTTP interface (e.g., "http://localhost:8123").
+	Addr string
+	// Database is the default database of the connection.
+	Database string
+	// Username and Password authenticate the connection. The server uses
+	// the "default" user if Username is empty.
+	Username string
+	Password string
+	// TLS enables TLS with the given configuration if not nil. It is also
+	// used for https URLs.
+	TLS *tls.Config
+	// Timeout for the whole check. If zero, the deadline of the check
+	// context applies.
+	Timeout time.Duration
+}
+
+// ClickHouseCheck returns a Check that executes "SELECT 1" on the
+// ClickHouse server, over the HTTP interface if options.Addr is an http or
+// https URL and over the native protocol otherwise.
+func ClickHouseCheck(options ClickHouseOptions) func(ctx context.Context) error {
+	if strings.HasPrefix(options.Addr, "http://") || strings.HasPrefix(options.Addr, "https://") {
+		return clickHouseHTTPCheck(options)
+	}
+	return func(ctx context.Context) error {
+		ctx, cancel := withOptionalTimeout(ctx, options.Timeout)
+		defer cancel()
+		return clickHouseNativeQuery(ctx, options)
+	}
+}
+
+func clickHouseHTTPCheck(options ClickHouseOptions) func(ctx context.Context) error {
+	client := &http.Client{
+		Timeout:   options.Timeout,
+		Transport: &http.Transport{TLSClientConfig: options.TLS},
+	}
+	query := url.Values{"query": {"SELECT 1"}}
+	if options.Database != "" {
+		query.Set("database", options.Database)
+	}
+	endpoint := strings.TrimSuffix(options.Addr, "/") + "/?" + query.Encode()
+	return func(ctx context.Context) error {
+		ctx, cancel := withOptionalTimeout(ctx, options.Timeout)
+		defer cancel()
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+		if err != nil {
+			return err
+		}
+		if options.Username != "" {
+			req.Header.Set("X-ClickHouse-User", options.Username)
+			req.Header.Set("X-ClickHouse-Key", options.Password)
+		}
+		resp, err := client.Do(req)
+		if err != nil {
+			return err
+		}
+		defer resp.Body.Close()
+		body, err := io.ReadAll(io.LimitReader(resp.Body, 4<<10))
+		if err != nil {
+			return err
+		}
+		if resp.StatusCode != http.StatusOK {
+			// Errors are returned as "Code: 516. DB::Exception: ...".
+			message, _, _ := strings.Cut(strings.TrimSpace(string(body)), "\n")
+			return fmt.Errorf("returned status %d: %s", resp.StatusCode, message)
+		}
+		if result := strings.TrimSpace(string(body)); result != "1" {
+			return fmt.Errorf("unexpected result %q", result)
+		}
+		return nil
+	}
+}
+
+// Packet types and the protocol revision of the ClickHouse native protocol.
+// Revision 54401 is old enough that the handshake needs no addendum, but
+// supported by all current servers.
+const (
+	clickHouseHello       = 0
+	clickHouseQuery       = 1
+	clickHouseData        = 2 // sent by the client
+	clickHouseServerData  = 1
+	clickHouseException   = 2
+	clickHouseProgress    = 3
+	clickHouseEndOfStream = 5
+	clickHouseProfileInfo = 6
+	clickHouseRevision    = 54401
+)
+
+// clickHouseNativeQuery performs the handshake of the native protocol,
+// executes "SELECT 1" and reads the result until the end of the stream.
+func clickHouseNativeQuery(ctx context.Context, options ClickHouseOptions) error {
+	config := options.TLS
+	if config != nil && config.ServerName == "" {
+		host, _, _ := net.SplitHostPort(options.Addr)
+		config = config.Clone()
+		config.ServerName = host
+	}
+	conn, err := dialTCP(ctx, options.Addr, config)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	username := options.Username
+	if username == "" {
+		username = "default"
+	}
+	hello := appendUvarint(nil, clickHouseHello)
+	hello = appendClickHouseString(hello, "go-healthcheck")
+	hello = appendUvarint(hello, 1) // client version major
+	hello = appendUvarint(hello, 0) // client version minor
+	hello = appendUvarint(hello, clickHouseRevision)
+	hello = appendClickHouseString(hello, options.Database)
+	hello = appendClickHouseString(hello, username)
+	hello = appendClickHouseString(hello, options.Password)
+	if _, err := conn.Write(hello); err != nil {
+		return err
+	}
+	r := bufio.NewReader(conn)
+	packet, err := readClickHousePacket(r)
+	if err != nil {
+		return err
+	}
+	if packet != clickHouseHello {
+		return fmt.Errorf("unexpected handshake response packet %d", packet)
+	}
+	// Server name, version major and minor, revision, time zone, display name
+	// and version patch.
+	if _, err := readClickHouseString(r); err != nil {
+		return err
+	}
+	var revision uint64
+	for i := 0; i < 3; i++ {
+		if revision, err = binary.ReadUvarint(r); err != nil {
+			return err
+		}
+	}
+	if revision > clickHouseRevision {
+		revision = clickHouseRevision
+	}
+	for _, minRevision := range []uint64{54058, 54372} {
+		if revision >= minRevision {
+			if _, err := readClickHouseString(r); err != nil {
+				return err
+			}
+		}
+	}
+	if revision >= 54401 {
+		if _, err := binary.ReadUvarint(r); err != nil {
+			return err
+		}
+	}
+	if _, err := conn.Write(appendClickHouseQuery(nil, revision, username, "SELECT 1")); err != nil {
+		return err
+	}
+
+	result := false
+	for {
+		packet, err := readClickHousePacket(r)
+		if err != nil {
+			return err
+		}
+		switch packet {
+		case clickHouseServerData:
+			values, err := readClickHouseBlock(r)
+			if err != nil {
+				return err
+			}
+			for _, value := range values {
+				if value != 1 {
+					return fmt.Errorf("unexpected result %d", value)
+				}
+				result = true
+			}
+		case clickHouseProgress:
+			// Rows, bytes and total rows.
+			if err := skipUvarints(r, 3); err != nil {
+				return err
+			}
+		case clickHouseProfileInfo:
+			// Rows, blocks, bytes, applied limit, rows before limit and
+			// calculated rows before limit.
+			if err := skipUvarints(r, 6); err != nil {
+				return err
+			}
+		case clickHouseEndOfStream:
+			if !result {
+				return errors.New("empty result")
+			}
+			return nil
+		default:
+			return fmt.Errorf("unexpected query response packet %d", packet)
+		}
+	}
+}
+
+// appendClickHouseQuery appends a Query packet for query, followed by the
+// empty Data packet that terminates the (absent) external tables.
+func appendClickHouseQuery(b []byte, revision uint64, username, query string) []byte {
+	b = appendUvarint(b, clickHouseQuery)
+	b = appendClickHouseString(b, "") // query id
+	if revision >= 54032 {
+		b = append(b, 1)                                // initial query
+		b = appendClickHouseString(b, username)         // initial user
+		b = appendClickHouseString(b, "")               // initial query id
+		b = appendClickHouseString(b, "0.0.0.0:0")      // initial address
+		b = append(b, 1)                                // TCP interface
+		b = appendClickHouseString(b, "")               // OS user
+		b = appendClickHouseString(b, "")               // client hostname
+		b = appendClickHouseString(b, "go-healthcheck") // client name
+		b = appendUvarint(b, 1)                         // client version major
+		b = appendUvarint(b, 0)                         // client version minor
+		b = appendUvarint(b, revision)
+		if revision >= 54060 {
+			b = appendClickHouseString(b, "") // quota key
+		}
+		if revision >= 54401 {
+			b = appendUvarint(b, 0) // client version patch
+		}
+	}
+	b = appendClickHouseString(b, "") // end of settings
+	b = appendUvarint(b, 2)           // stage: complete
+	b = appendUvarint(b, 0)           // no compression
+	b = appendClickHouseString(b, query)
+
+	b = appendUvarint(b, clickHouseData)
+	b = appendClickHouseString(b, "") // table name
+	return appendClickHouseBlockInfo(b, 0, 0)
+}
+
+// appendClickHouseBlockInfo appends the block info and the dimensions of a
+// block without data.
+func appendClickHouseBlockInfo(b []byte, columns, rows uint64) []byte {
+	b = appendUvarint(b, 1) // field 1: is overflows
+	b = append(b, 0)
+	b = appendUvarint(b, 2) // field 2: bucket number
+	b = append(b, 0xff, 0xff, 0xff, 0xff)
+	b = appendUvarint(b, 0) // end of block info
+	b = appendUvarint(b, columns)
+	return appendUvarint(b, rows)
+}
+
+// readClickHouseBlock reads the content of a Data packet and returns the
+// values of its UInt8 column, as in the result of "SELECT 1".
+func readClickHouseBlock(r *bufio.Reader) ([]byte, error) {
+	if _, err := readClickHouseString(r); err != nil { // table name
+		return nil, err
+	}
+	for {
+		field, err := binary.ReadUvarint(r)
+		if err != nil {
+			return nil, err
+		}
+		if field == 0 {
+			break
+		}
+		var size int
+		switch field {
+		case 1: // is overflows
+			size = 1
+		case 2: // bucket number
+			size = 4
+		default:
+			return nil, fmt.Errorf("unsupported block info field %d", field)
+		}
+		if _, err := r.Discard(size); err != nil {
+			return nil, err
+		}
+	}
+	columns, err := binary.ReadUvarint(r)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := binary.ReadUvarint(r)
+	if err != nil {
+		return nil, err
+	}
+	if columns > 1 || rows > 1<<10 {
+		return nil, fmt.Errorf("unexpected result of %d columns and %d rows", columns, rows)
+	}
+	if columns == 0 {
+		return nil, nil
+	}
+	if _, err := readClickHouseString(r); err != nil { // column name
+		return nil, err
+	}
+	typ, err := readClickHouseString(r)
+	if err != nil {
+		return nil, err
+	}
+	if rows == 0 {
+		return nil, nil
+	}
+	if typ != "UInt8" {
+		return nil, fmt.Errorf("unexpected result type %s", typ)
+	}
+	values := make([]byte, rows)
+	if _, err := io.ReadFull(r, values); err != nil {
+		return nil, err
+	}
+	return values, nil
+}
+
+func skipUvarints(r *bufio.Reader, n int) error {
+	for i := 0; i < n; i++ {
+		if _, err := binary.ReadUvarint(r); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// readClickHousePacket reads the type of the next packet. Exception packets
+// are returned as error.
+func readClickHousePacket(r *bufio.Reader) (uint64, error) {
+	packet, err := binary.ReadUvarint(r)
+	if err != nil {
+		return 0, err
+	}
+	if packet != clickHouseException {
+		return packet, nil
+	}
+	var code int32
+	if err := binary.Read(r, binary.LittleEndian, &code); err != nil {
+		return 0, err
+	}
+	if _, err := readClickHouseString(r); err != nil { // exception name
+		return 0, err
+	}
+	message, err := readClickHouseString(r)
+	if err != nil {
+		return 0, err
+	}
+	return 0, fmt.Errorf("code %d: %s", code, message)
+}
+
+func appendClickHouseString(b []byte, s string) []byte {
+	return append(appendUvarint(b, uint64(len(s))), s...)
+}
+
+func readClickHouseString(r *bufio.Reader) (string, error) {
+	n, err := binary.ReadUvarint(r)
+	if err != nil {
+		return "", err
+	}
+	if n > 1<<20 {
+		return "", errors.New("string too large")
+	}
+	b := make([]byte, n)
+	if _, err := io.ReadFull(r, b); err != nil {
+		return "", err
+	}
+	return string(b), nil
+}
@@ -0,0 +1,183 @@
+package healthcheck
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// readClickHouseQuery reads a Query packet and the following empty Data
+// packet in the order a server of revision 54401 does, and returns the
+// query.
+func readClickHouseQuery(r *bufio.Reader) (string, error) {
+	str := func() string {
+		s, _ := readClickHouseString(r)
+		return s
+	}
+	uvarint := func() uint64 {
+		v, _ := binary.ReadUvarint(r)
+		return v
+	}
+	if packet := uvarint(); packet != clickHouseQuery {
+		return "", fmt.Errorf("packet %d, want Query", packet)
+	}
+	str() // query id
+	if kind, _ := r.ReadByte(); kind != 1 {
+		return "", fmt.Errorf("query kind %d", kind)
+	}
+	str() // initial user
+	str() // initial query id
+	str() // initial address
+	if iface, _ := r.ReadByte(); iface != 1 {
+		return "", fmt.Errorf("interface %d", iface)
+	}
+	str() // OS user
+	str() // client hostname
+	str() // client name
+	uvarint()
+	uvarint()
+	if revision := uvarint(); revision != clickHouseRevision {
+		return "", fmt.Errorf("client info revision %d", revision)
+	}
+	str()     // quota key
+	uvarint() // version patch
+	if setting := str(); setting != "" {
+		return "", fmt.Errorf("setting %q", setting)
+	}
+	if stage := uvarint(); stage != 2 {
+		return "", fmt.Errorf("stage %d", stage)
+	}
+	if compression := uvarint(); compression != 0 {
+		return "", fmt.Errorf("compression %d", compression)
+	}
+	query := str()
+	if packet := uvarint(); packet != clickHouseData {
+		return "", fmt.Errorf("packet %d, want Data", packet)
+	}
+	values, err := readClickHouseBlock(r)
+	if err != nil || len(values) != 0 {
+		return "", fmt.Errorf("external tables block %v: %v", values, err)
+	}
+	return query, nil
+}
+
+// serveClickHouse accepts one connection on ln, completes the handshake
+// and answers the query with reply.
+func serveClickHouse(t *testing.T, ln net.Listener, reply func(w io.Writer)) {
+	conn, err := ln.Accept()
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+	r := bufio.NewReader(conn)
+	if packet, _ := binary.ReadUvarint(r); packet != clickHouseHello {
+		t.Errorf("packet %d, want Hello", packet)
+		return
+	}
+	readClickHouseString(r)
+	binary.ReadUvarint(r)
+	binary.ReadUvarint(r)
+	binary.ReadUvarint(r)
+	readClickHouseString(r)
+	if user, _ := readClickHouseString(r); user != "default" {
+		t.Errorf("user %q, want default", user)
+	}
+	readClickHouseString(r)
+
+	hello := appendUvarint(nil, clickHouseHello)
+	hello = appendClickHouseString(hello, "ClickHouse")
+	hello = appendUvarint(hello, 24)
+	hello = appendUvarint(hello, 8)
+	hello = appendUvarint(hello, 54470)
+	hello = appendClickHouseString(hello, "UTC")
+	hello = appendClickHouseString(hello, "clickhouse-1")
+	hello = appendUvarint(hello, 1)
+	conn.Write(hello)
+
+	query, err := readClickHouseQuery(r)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if query != "SELECT 1" {
+		t.Errorf("query %q, want SELECT 1", query)
+	}
+	reply(conn)
+}
+
+func clickHouseBlock(typ string, values ...byte) []byte {
+	b := appendUvarint(nil, clickHouseServerData)
+	b = appendClickHouseString(b, "")
+	b = appendClickHouseBlockInfo(b, 1, uint64(len(values)))
+	b = appendClickHouseString(b, "1")
+	b = appendClickHouseString(b, typ)
+	return append(b, values...)
+}
+
+func TestClickHouseNativeCheck(t *testing.T) {
+	progress := append(appendUvarint(nil, clickHouseProgress), 1, 1, 0)
+	profileInfo := append(appendUvarint(nil, clickHouseProfileInfo), 1, 1, 1, 0, 0, 0)
+	end := appendUvarint(nil, clickHouseEndOfStream)
+	exception := appendUvarint(nil, clickHouseException)
+	exception = append(exception, 241, 0, 0, 0)
+	exception = appendClickHouseString(exception, "DB::Exception")
+	exception = appendClickHouseString(exception, "Memory limit exceeded")
+	tests := []struct {
+		name    string
+		packets [][]byte
+		wantErr string
+	}{
+		{"ok", [][]byte{clickHouseBlock("UInt8"), progress, clickHouseBlock("UInt8", 1), profileInfo, end}, ""},
+		{"exception", [][]byte{exception}, "code 241: Memory limit exceeded"},
+		{"empty result", [][]byte{clickHouseBlock("UInt8"), end}, "empty result"},
+		{"wrong result", [][]byte{clickHouseBlock("UInt8", 2), end}, "unexpected result 2"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ln, err := net.Listen("tcp", "127.0.0.1:0")
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer ln.Close()
+			go serveClickHouse(t, ln, func(w io.Writer) {
+				for _, packet := range test.packets {
+					w.Write(packet)
+				}
+			})
+			err = ClickHouseCheck(ClickHouseOptions{Addr: ln.Addr().String(), Timeout: time.Second})(context.Background())
+			if test.wantErr == "" && err != nil {
+				t.Fatal(err)
+			}
+			if test.wantErr != "" && (err == nil || !strings.Contains(err.Error(), test.wantErr)) {
+				t.Fatalf("error %v, want %q", err, test.wantErr)
+			}
+		})
+	}
+}
+
+func TestClickHouseHTTPCheck(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("query") != "SELECT 1" || r.Header.Get("X-ClickHouse-User") != "reader" {
+			w.WriteHeader(http.StatusForbidden)
+			io.WriteString(w, "Code: 516. DB::Exception: reader: Authentication failed\n")
+			return
+		}
+		io.WriteString(w, "1\n")
+	}))
+	defer server.Close()
+	if err := ClickHouseCheck(ClickHouseOptions{Addr: server.URL, Username: "reader"})(context.Background()); err != nil {
+		t.Error(err)
+	}
+	err := ClickHouseCheck(ClickHouseOptions{Addr: server.URL})(context.Background())
+	if err == nil || !strings.Contains(err.Error(), "returned status 403: Code: 516") {
+		t.Errorf("error %v, want status 403", err)
+	}
+}
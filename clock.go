@@ -0,0 +1,126 @@
+package healthcheck
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// ntpEpochOffset is the number of seconds between the NTP epoch (1900) and
+// the Unix epoch (1970).
+const ntpEpochOffset = 2208988800
+
+// ClockSkewCheck returns a Check that compares the local clock against the
+// clock of server and fails if they drift apart by more than maxSkew. server
+// is either an NTP server (e.g., "pool.ntp.org" or "ntp:123"), which is
+// queried with SNTP, or an HTTP(S) URL whose Date header is used instead.
+// As the Date header has a resolution of one second, maxSkew should be well
+// above a second for HTTP servers.
+func ClockSkewCheck(server string, maxSkew time.Duration, timeout time.Duration) func(ctx context.Context) error {
+	query := ntpOffset
+	if strings.HasPrefix(server, "http://") || strings.HasPrefix(server, "https://") {
+		client := &http.Client{Timeout: timeout}
+		query = func(ctx context.Context, server string) (time.Duration, error) {
+			return httpDateOffset(ctx, client, server)
+		}
+	} else if _, _, err := net.SplitHostPort(server); err != nil {
+		server = net.JoinHostPort(server, "123")
+	}
+	return func(ctx context.Context) error {
+		ctx, cancel := withOptionalTimeout(ctx, timeout)
+		defer cancel()
+		offset, err := query(ctx, server)
+		if err != nil {
+			return err
+		}
+		if offset > maxSkew || offset < -maxSkew {
+			return fmt.Errorf("clock offset %s exceeds %s", offset, maxSkew)
+		}
+		return nil
+	}
+}
+
+// ntpOffset returns the offset of the clock of the NTP server at addr
+// relative to the local clock.
+func ntpOffset(ctx context.Context, addr string) (time.Duration, error) {
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "udp", addr)
+	if err != nil {
+		return 0, err
+	}
+	defer conn.Close()
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+	request := make([]byte, 48)
+	request[0] = 0x23 // version 4, client mode
+	sent := time.Now()
+	binary.BigEndian.PutUint64(request[40:], ntpTimestamp(sent))
+	if _, err := conn.Write(request); err != nil {
+		return 0, err
+	}
+	response := make([]byte, 48)
+	for {
+		n, err := conn.Read(response)
+		if err != nil {
+			return 0, err
+		}
+		received := time.Now()
+		// Ignore datagrams that do not answer the request.
+		if n < 48 || response[0]&0x7 != 4 || !bytes.Equal(response[24:32], request[40:48]) {
+			continue
+		}
+		if response[1] == 0 {
+			return 0, fmt.Errorf("NTP server refused the request (%s)", strings.TrimRight(string(response[12:16]), "\x00"))
+		}
+		if response[0]>>6 == 3 {
+			return 0, errors.New("NTP server is not synchronized")
+		}
+		serverReceived := ntpTime(binary.BigEndian.Uint64(response[32:]))
+		serverSent := ntpTime(binary.BigEndian.Uint64(response[40:]))
+		return (serverReceived.Sub(sent) + serverSent.Sub(received)) / 2, nil
+	}
+}
+
+// httpDateOffset returns the offset of the Date header of the response from
+// url relative to the local clock at the middle of the round trip.
+func httpDateOffset(ctx context.Context, client *http.Client, url string) (time.Duration, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodHead, url, nil)
+	if err != nil {
+		return 0, err
+	}
+	sent := time.Now()
+	resp, err := client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	resp.Body.Close()
+	received := time.Now()
+	date, err := http.ParseTime(resp.Header.Get("Date"))
+	if err != nil {
+		return 0, fmt.Errorf("invalid Date header: %w", err)
+	}
+	return date.Sub(sent.Add(received.Sub(sent) / 2)), nil
+}
+
+func ntpTimestamp(t time.Time) uint64 {
+	seconds := uint64(t.Unix() + ntpEpochOffset)
+	fraction := uint64(t.Nanosecond()) << 32 / 1e9
+	return seconds<<32 | fraction
+}
+
+func ntpTime(timestamp uint64) time.Time {
+	seconds := int64(timestamp>>32) - ntpEpochOffset
+	// Timestamps wrap in 2036; earlier values belong to the next era.
+	if timestamp>>63 == 0 {
+		seconds += 1 << 32
+	}
+	nanoseconds := int64(timestamp&0xFFFFFFFF) * 1e9 >> 32
+	return time.Unix(seconds, nanoseconds)
+}
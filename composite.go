@@ -0,0 +1,80 @@
+package healthcheck
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+// AllOf returns a Check that executes checks concurrently and fails if any
+// of them fails. The failed checks are reported as causes (see CauseError).
+// It is degraded (see Degraded) if all failures are degraded.
+func AllOf(checks ...Check) func(ctx context.Context) error {
+	return func(ctx context.Context) error {
+		failed, degraded := runChecks(ctx, checks)
+		if len(failed) == 0 {
+			return nil
+		}
+		err := &CauseError{Name: "all of", Causes: failed}
+		if degraded {
+			return Degraded(err)
+		}
+		return err
+	}
+}
+
+// AnyOf returns a Check that executes checks concurrently and fails only if
+// all of them fail, e.g. for redundant endpoints of which one is enough. As
+// the redundancy is lost, it is degraded (see Degraded) if some but not all
+// checks fail. The failed checks are reported as causes (see CauseError).
+func AnyOf(checks ...Check) func(ctx context.Context) error {
+	return func(ctx context.Context) error {
+		failed, _ := runChecks(ctx, checks)
+		if len(failed) == 0 {
+			return nil
+		}
+		err := &CauseError{Name: "any of", Causes: failed}
+		if len(failed) < len(checks) {
+			return Degraded(err)
+		}
+		return err
+	}
+}
+
+// runChecks executes checks concurrently, each within its own timeout if it
+// has one. It returns the failed checks in the order of checks, and whether
+// all failures are degraded.
+func runChecks(ctx context.Context, checks []Check) ([]*CauseError, bool) {
+	errs := make([]error, len(checks))
+	var wg sync.WaitGroup
+	for i, check := range checks {
+		wg.Add(1)
+		go func(i int, check Check) {
+			defer wg.Done()
+			ctx := ctx
+			if check.Timeout > 0 {
+				var cancel context.CancelFunc
+				ctx, cancel = context.WithTimeout(ctx, check.Timeout)
+				defer cancel()
+			}
+			errs[i] = check.Check(ctx)
+		}(i, check)
+	}
+	wg.Wait()
+	var failed []*CauseError
+	degraded := true
+	for i, err := range errs {
+		if err == nil {
+			continue
+		}
+		degraded = degraded && IsDegraded(err)
+		cause := &CauseError{Name: checks[i].Name, Err: err}
+		// Nested composite checks keep their own causes.
+		var nested *CauseError
+		if errors.As(err, &nested) {
+			cause.Err, cause.Causes = nested.Err, nested.Causes
+		}
+		failed = append(failed, cause)
+	}
+	return failed, degraded
+}
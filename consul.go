@@ -0,0 +1,76 @@
+package healthcheck
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// ConsulAgentCheck returns a Check that verifies the Consul agent at
+// agentURL (e.g., "http://127.0.0.1:8500") is reachable and knows a cluster
+// leader. If serviceID is not empty, the check also fails unless the service
+// is registered with the agent and its health checks are passing; a service
+// in warning state is reported as degraded (see Degraded). token is sent as
+// ACL token if not empty.
+func ConsulAgentCheck(agentURL, serviceID, token string, timeout time.Duration) func(ctx context.Context) error {
+	client := http.Client{Timeout: timeout}
+	base := strings.TrimSuffix(agentURL, "/")
+	get := func(ctx context.Context, path string, v interface{}) (int, error) {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, base+path, nil)
+		if err != nil {
+			return 0, err
+		}
+		if token != "" {
+			req.Header.Set("X-Consul-Token", token)
+		}
+		resp, err := client.Do(req)
+		if err != nil {
+			return 0, err
+		}
+		defer resp.Body.Close()
+		if err := json.NewDecoder(resp.Body).Decode(v); err != nil && resp.StatusCode != http.StatusNotFound {
+			return resp.StatusCode, fmt.Errorf("invalid response (status %d): %w", resp.StatusCode, err)
+		}
+		return resp.StatusCode, nil
+	}
+	return func(ctx context.Context) error {
+		var leader string
+		code, err := get(ctx, "/v1/status/leader", &leader)
+		if err != nil {
+			return err
+		}
+		if code != http.StatusOK {
+			return fmt.Errorf("agent returned status %d", code)
+		}
+		if leader == "" {
+			return errors.New("cluster has no leader")
+		}
+		if serviceID == "" {
+			return nil
+		}
+		var health struct {
+			AggregatedStatus string
+		}
+		code, err = get(ctx, "/v1/agent/health/service/id/"+url.PathEscape(serviceID), &health)
+		if err != nil {
+			return err
+		}
+		switch code {
+		case http.StatusOK:
+			return nil
+		case http.StatusNotFound:
+			return fmt.Errorf("service %s is not registered", serviceID)
+		case http.StatusTooManyRequests:
+			return Degraded(fmt.Errorf("service %s is in %s state", serviceID, health.AggregatedStatus))
+		case http.StatusServiceUnavailable:
+			return fmt.Errorf("service %s is in %s state", serviceID, health.AggregatedStatus)
+		default:
+			return fmt.Errorf("agent returned status %d", code)
+		}
+	}
+}
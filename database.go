@@ -0,0 +1,260 @@
+package healthcheck
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// MigrationOptions configures MigrationVersionCheck.
+type MigrationOptions struct {
+	// Tool selects the layout of the migrations table: "golang-migrate"
+	// (the default) or "goose".
+	Tool string
+	// Table is the name of the migrations table. It defaults to
+	// "schema_migrations" for golang-migrate and "goose_db_version" for
+	// goose.
+	Table string
+	// MinVersion is the minimum schema version the application requires.
+	MinVersion int64
+	// Timeout for the whole check. If zero, the deadline of the check
+	// context applies.
+	Timeout time.Duration
+}
+
+// MigrationVersionCheck returns a Check that reads the applied schema version
+// from the migrations table of database and fails if it is lower than
+// options.MinVersion, or if golang-migrate flagged the last migration as
+// dirty (i.e., it failed halfway).
+func MigrationVersionCheck(database *sql.DB, options MigrationOptions) func(ctx context.Context) error {
+	return func(ctx context.Context) error {
+		ctx, cancel := withOptionalTimeout(ctx, options.Timeout)
+		defer cancel()
+		if database == nil {
+			return fmt.Errorf("database is nil")
+		}
+		var version int64
+		var dirty bool
+		var err error
+		switch options.Tool {
+		case "", "golang-migrate":
+			version, dirty, err = migrateVersion(ctx, database, options.Table)
+		case "goose":
+			version, err = gooseVersion(ctx, database, options.Table)
+		default:
+			return fmt.Errorf("unsupported migration tool %q", options.Tool)
+		}
+		if err != nil {
+			return err
+		}
+		if dirty {
+			return fmt.Errorf("schema version %d is dirty", version)
+		}
+		if version < options.MinVersion {
+			return fmt.Errorf("schema version %d < %d", version, options.MinVersion)
+		}
+		return nil
+	}
+}
+
+// migrateVersion reads the single row of a golang-migrate table.
+func migrateVersion(ctx context.Context, database *sql.DB, table string) (int64, bool, error) {
+	if table == "" {
+		table = "schema_migrations"
+	}
+	var version int64
+	var dirty bool
+	err := database.QueryRowContext(ctx, "SELECT version, dirty FROM "+table+" LIMIT 1").Scan(&version, &dirty)
+	if err == sql.ErrNoRows {
+		return 0, false, nil
+	}
+	return version, dirty, err
+}
+
+// gooseVersion returns the latest applied version of a goose table, which
+// records rollbacks as rows with is_applied unset.
+func gooseVersion(ctx context.Context, database *sql.DB, table string) (int64, error) {
+	if table == "" {
+		table = "goose_db_version"
+	}
+	rows, err := database.QueryContext(ctx, "SELECT version_id, is_applied FROM "+table+" ORDER BY id DESC")
+	if err != nil {
+		return 0, err
+	}
+	defer rows.Close()
+	rolledBack := map[int64]bool{}
+	for rows.Next() {
+		var version int64
+		var applied bool
+		if err := rows.Scan(&version, &applied); err != nil {
+			return 0, err
+		}
+		if rolledBack[version] {
+			continue
+		}
+		if applied {
+			return version, nil
+		}
+		rolledBack[version] = true
+	}
+	return 0, rows.Err()
+}
+
+// DBPoolCheck returns a Check that inspects the connection pool statistics of
+// database and fails if more than maxInUsePercent of the maximum number of
+// open connections are in use, or if requests waited longer than
+// maxWaitDuration for a connection on average since the previous execution
+// of the check. A threshold of 0 disables the respective check; the usage is
+// not checked for pools without a limit of open connections.
+func DBPoolCheck(database *sql.DB, maxInUsePercent float64, maxWaitDuration time.Duration) func(ctx context.Context) error {
+	var (
+		mtx  sync.Mutex
+		last sql.DBStats
+	)
+	return func(ctx context.Context) error {
+		if database == nil {
+			return fmt.Errorf("database is nil")
+		}
+		stats := database.Stats()
+		mtx.Lock()
+		prev := last
+		last = stats
+		mtx.Unlock()
+		if maxInUsePercent > 0 && stats.MaxOpenConnections > 0 {
+			inUse := float64(stats.InUse) / float64(stats.MaxOpenConnections) * 100
+			if inUse > maxInUsePercent {
+				return fmt.Errorf("%d of %d connections in use (%.1f%% > %.1f%%)",
+					stats.InUse, stats.MaxOpenConnections, inUse, maxInUsePercent)
+			}
+		}
+		if waits := stats.WaitCount - prev.WaitCount; maxWaitDuration > 0 && waits > 0 {
+			wait := (stats.WaitDuration - prev.WaitDuration) / time.Duration(waits)
+			if wait > maxWaitDuration {
+				return fmt.Errorf("average wait for a connection %s > %s", wait, maxWaitDuration)
+			}
+		}
+		return nil
+	}
+}
+
+// postgresReplicationLagQuery returns whether the server is a replica and its
+// replay lag in seconds. A replica that replayed all WAL received while
+// streaming from the primary is not lagging, even if the primary did not
+// commit for a while. Without a streaming WAL receiver, e.g. when the replica
+// lost its primary, the lag is the time since the last replayed transaction.
+// Roles without pg_read_all_stats see the receiver but not its status.
+const postgresReplicationLagQuery = `SELECT pg_is_in_recovery(),
+	CASE WHEN pg_last_wal_receive_lsn() = pg_last_wal_replay_lsn()
+		AND EXISTS (SELECT 1 FROM pg_stat_wal_receiver WHERE status IS NULL OR status = 'streaming') THEN 0
+	ELSE EXTRACT(EPOCH FROM now() - pg_last_xact_replay_timestamp()) END`
+
+// PostgresReplicationLagCheck returns a Check that fails if the PostgreSQL
+// server behind database is not a replica, or if it lags behind the primary
+// by more than maxLag. It requires PostgreSQL 10 or later.
+func PostgresReplicationLagCheck(database *sql.DB, maxLag time.Duration, timeout time.Duration) func(ctx context.Context) error {
+	return func(ctx context.Context) error {
+		ctx, cancel := withOptionalTimeout(ctx, timeout)
+		defer cancel()
+		if database == nil {
+			return fmt.Errorf("database is nil")
+		}
+		var replica bool
+		var lag sql.NullFloat64
+		if err := database.QueryRowContext(ctx, postgresReplicationLagQuery).Scan(&replica, &lag); err != nil {
+			return err
+		}
+		if !replica {
+			return fmt.Errorf("server is not a replica")
+		}
+		if !lag.Valid {
+			return fmt.Errorf("replica has not replayed any transaction")
+		}
+		if lagDuration := time.Duration(lag.Float64 * float64(time.Second)); lagDuration > maxLag {
+			return fmt.Errorf("replication lag %s > %s", lagDuration.Round(time.Millisecond), maxLag)
+		}
+		return nil
+	}
+}
+
+// MySQLReplicaCheck returns a Check that inspects SHOW REPLICA STATUS of the
+// MySQL or MariaDB server behind database and fails if the server is not a
+// replica, if its IO or SQL thread is not running, or if it lags behind the
+// source by more than maxLag. Servers that do not support SHOW REPLICA
+// STATUS are queried with SHOW SLAVE STATUS. With multi-source replication,
+// every channel must be healthy.
+func MySQLReplicaCheck(database *sql.DB, maxLag time.Duration, timeout time.Duration) func(ctx context.Context) error {
+	return func(ctx context.Context) error {
+		ctx, cancel := withOptionalTimeout(ctx, timeout)
+		defer cancel()
+		if database == nil {
+			return fmt.Errorf("database is nil")
+		}
+		channels, err := mysqlReplicaStatus(ctx, database, "SHOW REPLICA STATUS")
+		if err != nil {
+			var legacyErr error
+			if channels, legacyErr = mysqlReplicaStatus(ctx, database, "SHOW SLAVE STATUS"); legacyErr != nil {
+				return err
+			}
+		}
+		if len(channels) == 0 {
+			return fmt.Errorf("server is not a replica")
+		}
+		for _, status := range channels {
+			// Column names depend on the server version.
+			field := func(name, legacyName string) string {
+				if value, ok := status[name]; ok {
+					return value
+				}
+				return status[legacyName]
+			}
+			if field("Replica_IO_Running", "Slave_IO_Running") != "Yes" {
+				return fmt.Errorf("replica IO thread is not running: %s", status["Last_IO_Error"])
+			}
+			if field("Replica_SQL_Running", "Slave_SQL_Running") != "Yes" {
+				return fmt.Errorf("replica SQL thread is not running: %s", status["Last_SQL_Error"])
+			}
+			seconds, err := strconv.ParseInt(field("Seconds_Behind_Source", "Seconds_Behind_Master"), 10, 64)
+			if err != nil {
+				return fmt.Errorf("replication lag is unknown")
+			}
+			if lag := time.Duration(seconds) * time.Second; lag > maxLag {
+				return fmt.Errorf("replication lag %s > %s", lag, maxLag)
+			}
+		}
+		return nil
+	}
+}
+
+// mysqlReplicaStatus returns the rows of a replica status statement as maps
+// of column names to values.
+func mysqlReplicaStatus(ctx context.Context, database *sql.DB, query string) ([]map[string]string, error) {
+	rows, err := database.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	columns, err := rows.Columns()
+	if err != nil {
+		return nil, err
+	}
+	var channels []map[string]string
+	for rows.Next() {
+		values := make([]sql.RawBytes, len(columns))
+		dest := make([]interface{}, len(columns))
+		for i := range values {
+			dest[i] = &values[i]
+		}
+		if err := rows.Scan(dest...); err != nil {
+			return nil, err
+		}
+		status := map[string]string{}
+		for i, column := range columns {
+			status[column] = string(values[i])
+		}
+		channels = append(channels, status)
+	}
+	return channels, rows.Err()
+}
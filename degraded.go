@@ -0,0 +1,41 @@
+package healthcheck
+
+import (
+	"context"
+	"errors"
+)
+
+type degradedError struct {
+	err error
+}
+
+func (e *degradedError) Error() string { return e.err.Error() }
+
+func (e *degradedError) Unwrap() error { return e.err }
+
+// Degraded marks err as a soft failure. A check returning an error created by
+// Degraded is reported as degraded (see StatusDegraded) instead of down.
+func Degraded(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &degradedError{err: err}
+}
+
+// IsDegraded reports whether err was marked as a soft failure with Degraded.
+func IsDegraded(err error) bool {
+	var degraded *degradedError
+	return errors.As(err, &degraded)
+}
+
+// degradedInterceptor is an interceptor that reports checks failing
+// with a Degraded error as degraded rather than down.
+func degradedInterceptor(next interceptorFunc) interceptorFunc {
+	return func(ctx context.Context, name string, state checkState) checkState {
+		state = next(ctx, name, state)
+		if state.Status == StatusDown && IsDegraded(state.Result) {
+			state.Status = StatusDegraded
+		}
+		return state
+	}
+}
@@ -0,0 +1,204 @@
+package healthcheck
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// SetCheckDependencies declares that the named check depends on the checks
+// dependsOn, replacing its previous dependencies, e.g. a migration-version
+// check on the database check. While a dependency is down or skipped, the
+// check is not executed and reported as StatusSkipped, instead of piling
+// up redundant errors and timeouts. A check waits for dependencies that are
+// executing at the same time; otherwise the last result of a dependency
+// decides.
+func (c *AndictlCheckerConfig) SetCheckDependencies(name string, dependsOn ...string) {
+	if c.dependencies == nil {
+		c.dependencies = map[string][]string{}
+	}
+	c.dependencies[name] = dependsOn
+}
+
+// dependencyCycle returns the checks of a dependency cycle, or nil if there
+// is none.
+func dependencyCycle(dependencies map[string][]string) []string {
+	const (
+		visiting = 1
+		visited  = 2
+	)
+	state := map[string]int{}
+	var path []string
+	var visit func(name string) []string
+	visit = func(name string) []string {
+		switch state[name] {
+		case visiting:
+			for i, n := range path {
+				if n == name {
+					return append(path[i:len(path):len(path)], name)
+				}
+			}
+		case visited:
+			return nil
+		}
+		state[name] = visiting
+		path = append(path, name)
+		for _, dependency := range dependencies[name] {
+			if cycle := visit(dependency); cycle != nil {
+				return cycle
+			}
+		}
+		path = path[:len(path)-1]
+		state[name] = visited
+		return nil
+	}
+	for name := range dependencies {
+		if cycle := visit(name); cycle != nil {
+			return cycle
+		}
+	}
+	return nil
+}
+
+// dependencyTracker records the status of every check, so that dependent
+// checks can be skipped.
+type dependencyTracker struct {
+	dependencies map[string][]string
+	// dependedOn holds the checks other checks depend on.
+	dependedOn map[string]bool
+	mtx        sync.Mutex
+	statuses   map[string]AvailabilityStatus
+	// executed maps checks to the end of their last execution.
+	executed map[string]time.Time
+	// pending maps the checks due in the current evaluation to a channel
+	// closed once they finished.
+	pending map[string]chan struct{}
+}
+
+func newDependencyTracker(dependencies map[string][]string) *dependencyTracker {
+	dependedOn := map[string]bool{}
+	for _, names := range dependencies {
+		for _, name := range names {
+			dependedOn[name] = true
+		}
+	}
+	return &dependencyTracker{
+		dependencies: dependencies,
+		dependedOn:   dependedOn,
+		statuses:     map[string]AvailabilityStatus{},
+		executed:     map[string]time.Time{},
+		pending:      map[string]chan struct{}{},
+	}
+}
+
+// intercept is an interceptor skipping checks whose dependencies failed.
+func (dt *dependencyTracker) intercept(next interceptorFunc) interceptorFunc {
+	return func(ctx context.Context, name string, state checkState) checkState {
+		if dependency, status := dt.failedDependency(ctx, name); dependency != "" {
+			state = checkState{
+				Result: fmt.Errorf("skipped: depends on %q, which is %s", dependency, status),
+				Status: StatusSkipped,
+			}
+			dt.finish(name, state.Status, false)
+			return state
+		}
+		state = next(ctx, name, state)
+		dt.finish(name, state.Status, true)
+		return state
+	}
+}
+
+// failedDependency returns the first dependency of the named check that is
+// down or skipped, and its status. It waits for dependencies that are due in
+// the current evaluation.
+func (dt *dependencyTracker) failedDependency(ctx context.Context, name string) (string, AvailabilityStatus) {
+	for _, dependency := range dt.dependencies[name] {
+		dt.mtx.Lock()
+		done := dt.pending[dependency]
+		dt.mtx.Unlock()
+		if done != nil {
+			select {
+			case <-done:
+			case <-ctx.Done():
+			}
+		}
+		dt.mtx.Lock()
+		status := dt.statuses[dependency]
+		dt.mtx.Unlock()
+		if status == StatusDown || status == StatusSkipped {
+			return dependency, status
+		}
+	}
+	return "", ""
+}
+
+func (dt *dependencyTracker) finish(name string, status AvailabilityStatus, executed bool) {
+	dt.mtx.Lock()
+	defer dt.mtx.Unlock()
+	dt.statuses[name] = status
+	if executed {
+		dt.executed[name] = time.Now()
+	}
+	if done, ok := dt.pending[name]; ok {
+		close(done)
+		delete(dt.pending, name)
+	}
+}
+
+// begin marks the checks depended on that the engine will execute because
+// their cached results expired. The end of the last execution is recorded
+// after the engine took its timestamp, so a check marked pending is always
+// executed.
+func (dt *dependencyTracker) begin(checks []scheduledCheck, cacheDuration time.Duration) {
+	dt.mtx.Lock()
+	defer dt.mtx.Unlock()
+	expiry := time.Now().Add(-cacheDuration)
+	for _, check := range checks {
+		if !dt.dependedOn[check.Name] || check.refreshPeriod > 0 {
+			continue
+		}
+		if executed, ok := dt.executed[check.Name]; !ok || executed.Before(expiry) {
+			dt.pending[check.Name] = make(chan struct{})
+		}
+	}
+}
+
+// end releases the checks that are still pending.
+func (dt *dependencyTracker) end(checks []scheduledCheck) {
+	dt.mtx.Lock()
+	defer dt.mtx.Unlock()
+	for _, check := range checks {
+		if done, ok := dt.pending[check.Name]; ok {
+			close(done)
+			delete(dt.pending, check.Name)
+		}
+	}
+}
+
+// wrap returns e evaluating the checks of cfg with dependencies, or e itself
+// if dt is nil.
+func (dt *dependencyTracker) wrap(e engine, cfg engineConfig) engine {
+	if dt == nil {
+		return e
+	}
+	return &dependencyChecker{engine: e, tracker: dt, checks: cfg.checks, cacheDuration: cfg.cacheDuration}
+}
+
+// dependencyChecker marks the pending checks of each evaluation of the
+// wrapped engine.
+type dependencyChecker struct {
+	engine
+	tracker       *dependencyTracker
+	checks        []scheduledCheck
+	cacheDuration time.Duration
+	mtx           sync.Mutex
+}
+
+func (ck *dependencyChecker) Check(ctx context.Context) CheckerResult {
+	ck.mtx.Lock()
+	defer ck.mtx.Unlock()
+	ck.tracker.begin(ck.checks, ck.cacheDuration)
+	defer ck.tracker.end(ck.checks)
+	return ck.engine.Check(ctx)
+}
@@ -0,0 +1,28 @@
+package healthcheck
+
+import (
+	"context"
+	"crypto/tls"
+	"net"
+)
+
+// dialTCP connects to addr, over TLS if config is not nil, and applies the
+// deadline of ctx to the connection.
+func dialTCP(ctx context.Context, addr string, config *tls.Config) (net.Conn, error) {
+	var conn net.Conn
+	var err error
+	if config != nil {
+		dialer := tls.Dialer{Config: config}
+		conn, err = dialer.DialContext(ctx, "tcp", addr)
+	} else {
+		dialer := net.Dialer{}
+		conn, err = dialer.DialContext(ctx, "tcp", addr)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+	return conn, nil
+}
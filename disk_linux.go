@@ -0,0 +1,127 @@
+package healthcheck
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+)
+
+// DiskUsageCheck returns a Check that fails if more than maxUsedPercent of
+// the filesystem containing path is in use. Like df, blocks reserved for the
+// root user are not counted as available.
+func DiskUsageCheck(path string, maxUsedPercent float64) func(ctx context.Context) error {
+	return func(ctx context.Context) error {
+		stat, err := statfs(path)
+		if err != nil {
+			return err
+		}
+		used := stat.Blocks - stat.Bfree
+		if used+stat.Bavail == 0 {
+			return nil
+		}
+		usedPercent := float64(used) / float64(used+stat.Bavail) * 100
+		if usedPercent > maxUsedPercent {
+			return fmt.Errorf("disk usage of %s %.1f%% > %.1f%%", path, usedPercent, maxUsedPercent)
+		}
+		return nil
+	}
+}
+
+// InodeUsageCheck returns a Check that fails if more than maxUsedPercent of
+// the inodes of the filesystem containing path are in use. Filesystems that
+// allocate inodes dynamically and report no inode count always pass.
+func InodeUsageCheck(path string, maxUsedPercent float64) func(ctx context.Context) error {
+	return func(ctx context.Context) error {
+		stat, err := statfs(path)
+		if err != nil {
+			return err
+		}
+		if stat.Files == 0 {
+			return nil
+		}
+		usedPercent := float64(stat.Files-stat.Ffree) / float64(stat.Files) * 100
+		if usedPercent > maxUsedPercent {
+			return fmt.Errorf("inode usage of %s %.1f%% > %.1f%%", path, usedPercent, maxUsedPercent)
+		}
+		return nil
+	}
+}
+
+// MountCheck returns a Check that fails unless a filesystem is mounted at
+// path, e.g. a Kubernetes volume. If writable is set, the check also fails
+// if the filesystem is mounted read-only.
+func MountCheck(path string, writable bool) func(ctx context.Context) error {
+	return func(ctx context.Context) error {
+		target, err := filepath.EvalSymlinks(path)
+		if err != nil {
+			return err
+		}
+		if target, err = filepath.Abs(target); err != nil {
+			return err
+		}
+		f, err := os.Open("/proc/self/mountinfo")
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		mounted, readOnly := false, false
+		scanner := bufio.NewScanner(f)
+		for scanner.Scan() {
+			// Fields are separated from the superblock options by "-".
+			fields := strings.Fields(scanner.Text())
+			if len(fields) < 10 || unescapeMountPath(fields[4]) != target {
+				continue
+			}
+			// Later entries are mounted over earlier ones.
+			mounted, readOnly = true, false
+			for _, options := range []string{fields[5], fields[len(fields)-1]} {
+				for _, option := range strings.Split(options, ",") {
+					readOnly = readOnly || option == "ro"
+				}
+			}
+		}
+		if err := scanner.Err(); err != nil {
+			return err
+		}
+		if !mounted {
+			return fmt.Errorf("%s is not a mount point", path)
+		}
+		if writable && readOnly {
+			return fmt.Errorf("%s is mounted read-only", path)
+		}
+		return nil
+	}
+}
+
+// unescapeMountPath decodes the octal escapes (e.g., "\040" for a space) of
+// paths in /proc/self/mountinfo.
+func unescapeMountPath(path string) string {
+	if !strings.Contains(path, "\\") {
+		return path
+	}
+	var b strings.Builder
+	for i := 0; i < len(path); i++ {
+		if path[i] == '\\' && i+3 < len(path) {
+			if c, err := strconv.ParseUint(path[i+1:i+4], 8, 8); err == nil {
+				b.WriteByte(byte(c))
+				i += 3
+				continue
+			}
+		}
+		b.WriteByte(path[i])
+	}
+	return b.String()
+}
+
+func statfs(path string) (*syscall.Statfs_t, error) {
+	var stat syscall.Statfs_t
+	if err := syscall.Statfs(path, &stat); err != nil {
+		return nil, &os.PathError{Op: "statfs", Path: path, Err: err}
+	}
+	return &stat, nil
+}
@@ -0,0 +1,31 @@
+//go:build !linux
+
+package healthcheck
+
+import "context"
+
+// DiskUsageCheck returns a Check that fails if more than maxUsedPercent of
+// the filesystem containing path is in use. It is only supported on Linux.
+func DiskUsageCheck(path string, maxUsedPercent float64) func(ctx context.Context) error {
+	return func(ctx context.Context) error {
+		return errUnsupportedPlatform
+	}
+}
+
+// InodeUsageCheck returns a Check that fails if more than maxUsedPercent of
+// the inodes of the filesystem containing path are in use. It is only
+// supported on Linux.
+func InodeUsageCheck(path string, maxUsedPercent float64) func(ctx context.Context) error {
+	return func(ctx context.Context) error {
+		return errUnsupportedPlatform
+	}
+}
+
+// MountCheck returns a Check that fails unless a filesystem is mounted at
+// path, and, if writable is set, mounted read-write. It is only supported on
+// Linux.
+func MountCheck(path string, writable bool) func(ctx context.Context) error {
+	return func(ctx context.Context) error {
+		return errUnsupportedPlatform
+	}
+}
@@ -0,0 +1,145 @@
+package healthcheck
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DNSExpectOptions configures DNSExpectCheck.
+type DNSExpectOptions struct {
+	// Type is the record type to resolve: "A" (the default), "AAAA",
+	// "CNAME", "SRV" or "TXT".
+	Type string
+	// Expected holds the acceptable answers. For A and AAAA records these
+	// are IP addresses or CIDR ranges, for CNAME records host names and for
+	// SRV records "target:port" pairs; every answer must match one of them.
+	// For TXT records, every expected value must be among the answers.
+	Expected []string
+	// MinAnswers is the minimum number of answers, at least one.
+	MinAnswers int
+	// Timeout for the whole check. If zero, the deadline of the check
+	// context applies.
+	Timeout time.Duration
+}
+
+// DNSExpectCheck returns a Check that resolves the records of the given type
+// for host and fails unless the answers match the expectations of options.
+// This detects misconfigured split-horizon DNS, which resolves the host, but
+// to the wrong addresses. SRV records are looked up directly by host (e.g.,
+// "_ldap._tcp.example.com").
+func DNSExpectCheck(host string, options DNSExpectOptions) func(ctx context.Context) error {
+	var resolver net.Resolver
+	if options.MinAnswers < 1 {
+		options.MinAnswers = 1
+	}
+	recordType := options.Type
+	if recordType == "" {
+		recordType = "A"
+	}
+	var networks []*net.IPNet
+	var parseErr error
+	if recordType == "A" || recordType == "AAAA" {
+		for _, expected := range options.Expected {
+			if ip := net.ParseIP(expected); ip != nil {
+				expected += "/128"
+				if ip.To4() != nil {
+					expected = ip.String() + "/32"
+				}
+			}
+			_, network, err := net.ParseCIDR(expected)
+			if err != nil {
+				parseErr = fmt.Errorf("invalid expected address %q", expected)
+				break
+			}
+			networks = append(networks, network)
+		}
+	}
+	return func(ctx context.Context) error {
+		if parseErr != nil {
+			return parseErr
+		}
+		ctx, cancel := withOptionalTimeout(ctx, options.Timeout)
+		defer cancel()
+		var answers []string
+		matches := func(answer string) bool {
+			for _, expected := range options.Expected {
+				if strings.EqualFold(strings.TrimSuffix(answer, "."), strings.TrimSuffix(expected, ".")) {
+					return true
+				}
+			}
+			return false
+		}
+		switch recordType {
+		case "A", "AAAA":
+			addrs, err := resolver.LookupIPAddr(ctx, host)
+			if err != nil {
+				return err
+			}
+			for _, addr := range addrs {
+				if (addr.IP.To4() != nil) == (recordType == "AAAA") {
+					continue
+				}
+				answers = append(answers, addr.IP.String())
+			}
+			matches = func(answer string) bool {
+				ip := net.ParseIP(answer)
+				for _, network := range networks {
+					if network.Contains(ip) {
+						return true
+					}
+				}
+				return false
+			}
+		case "CNAME":
+			cname, err := resolver.LookupCNAME(ctx, host)
+			if err != nil {
+				return err
+			}
+			answers = append(answers, cname)
+		case "SRV":
+			_, records, err := resolver.LookupSRV(ctx, "", "", host)
+			if err != nil {
+				return err
+			}
+			for _, record := range records {
+				answers = append(answers, net.JoinHostPort(strings.TrimSuffix(record.Target, "."), strconv.Itoa(int(record.Port))))
+			}
+		case "TXT":
+			records, err := resolver.LookupTXT(ctx, host)
+			if err != nil {
+				return err
+			}
+			answers = records
+		default:
+			return fmt.Errorf("unsupported record type %q", recordType)
+		}
+		if len(answers) < options.MinAnswers {
+			return fmt.Errorf("%d %s answers, expected at least %d", len(answers), recordType, options.MinAnswers)
+		}
+		if recordType == "TXT" {
+			present := map[string]bool{}
+			for _, answer := range answers {
+				present[answer] = true
+			}
+			for _, expected := range options.Expected {
+				if !present[expected] {
+					return fmt.Errorf("missing TXT record %q", expected)
+				}
+			}
+			return nil
+		}
+		if len(options.Expected) == 0 {
+			return nil
+		}
+		for _, answer := range answers {
+			if !matches(answer) {
+				return fmt.Errorf("unexpected answer %s", answer)
+			}
+		}
+		return nil
+	}
+}
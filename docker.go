@@ -0,0 +1,63 @@
+package healthcheck
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"time"
+)
+
+// defaultDockerHost is the socket of the Docker daemon on Linux.
+const defaultDockerHost = "unix:///var/run/docker.sock"
+
+// DockerDaemonCheck returns a Check that pings the Docker daemon at host,
+// which has the format of DOCKER_HOST (e.g., "unix:///var/run/docker.sock"
+// or "tcp://docker:2376"). An empty host defaults to DOCKER_HOST, or to the
+// default socket if it is not set. TCP endpoints use TLS with config if it
+// is not nil.
+func DockerDaemonCheck(host string, config *tls.Config, timeout time.Duration) func(ctx context.Context) error {
+	if host == "" {
+		host = os.Getenv("DOCKER_HOST")
+	}
+	if host == "" {
+		host = defaultDockerHost
+	}
+	u, err := url.Parse(host)
+	if err != nil {
+		return func(ctx context.Context) error {
+			return err
+		}
+	}
+	switch u.Scheme {
+	case "unix":
+		return UnixSocketHTTPCheck(u.Path, "/_ping", timeout)
+	case "tcp":
+		scheme := "http"
+		if config != nil {
+			scheme = "https"
+		}
+		client := http.Client{Timeout: timeout, Transport: &http.Transport{TLSClientConfig: config}}
+		return func(ctx context.Context) error {
+			req, err := http.NewRequestWithContext(ctx, http.MethodGet, scheme+"://"+u.Host+"/_ping", nil)
+			if err != nil {
+				return err
+			}
+			resp, err := client.Do(req)
+			if err != nil {
+				return err
+			}
+			resp.Body.Close()
+			if resp.StatusCode != 200 {
+				return fmt.Errorf("returned status %d", resp.StatusCode)
+			}
+			return nil
+		}
+	default:
+		return func(ctx context.Context) error {
+			return fmt.Errorf("unsupported Docker host %q", host)
+		}
+	}
+}
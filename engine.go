@@ -0,0 +1,154 @@
+package healthcheck
+
+import (
+	"context"
+	"time"
+)
+
+// Check describes the health check of a component.
+type Check struct {
+	// Name is the unique name of the check.
+	Name string
+	// Timeout is a check specific timeout. It defaults to the global timeout
+	// (see WithTimeout).
+	Timeout time.Duration
+	// Check is the function checking the component.
+	Check func(ctx context.Context) error
+	// FailureThreshold is the number of consecutive failed executions after
+	// which the check is reported as down. Earlier failures are reported as
+	// up, with their error, so a single transient failure does not take the
+	// system down. Zero and one report every failure.
+	FailureThreshold int
+	// SuccessThreshold is the number of consecutive successful executions
+	// after which a check that was down is reported as up again, so an
+	// unstable dependency does not flap in and out of rotation. Zero and one
+	// report the first success.
+	SuccessThreshold int
+}
+
+// CheckResult holds the health information of a component.
+type CheckResult struct {
+	// Status is the availability status of the component.
+	Status AvailabilityStatus `json:"status"`
+	// Timestamp holds the time when the check was executed.
+	Timestamp *time.Time `json:"timestamp,omitempty"`
+	// Error contains the check error message, if the check failed.
+	Error *string `json:"error,omitempty"`
+}
+
+// CheckerResult holds the aggregated system availability status and the
+// results of the individual checks.
+type CheckerResult struct {
+	// Status is the aggregated system availability status.
+	Status AvailabilityStatus `json:"status"`
+	// Details contains the results of all checks by check name.
+	Details map[string]CheckResult `json:"details,omitempty"`
+}
+
+// Option configures the checker (see AndictlCheckerConfig.AddCheck).
+type Option func(cfg *engineConfig)
+
+// WithCheck registers a check.
+func WithCheck(check Check) Option {
+	return func(cfg *engineConfig) {
+		cfg.checks = append(cfg.checks, scheduledCheck{Check: check})
+	}
+}
+
+// WithPeriodicCheck registers a check that is executed in the background
+// every refreshPeriod, starting after initialDelay, instead of on every
+// request.
+func WithPeriodicCheck(refreshPeriod, initialDelay time.Duration, check Check) Option {
+	return func(cfg *engineConfig) {
+		cfg.checks = append(cfg.checks, scheduledCheck{Check: check, refreshPeriod: refreshPeriod, initialDelay: initialDelay})
+	}
+}
+
+// WithCacheDuration sets how long the results of the checks are reused
+// before the checks are executed again.
+func WithCacheDuration(duration time.Duration) Option {
+	return func(cfg *engineConfig) {
+		cfg.cacheDuration = duration
+	}
+}
+
+// WithTimeout sets the global timeout applied to all checks.
+func WithTimeout(timeout time.Duration) Option {
+	return func(cfg *engineConfig) {
+		cfg.timeout = timeout
+	}
+}
+
+// WithStatusListener registers a function that is invoked when the
+// aggregated system status changes.
+func WithStatusListener(listener func(ctx context.Context, status AvailabilityStatus)) Option {
+	return func(cfg *engineConfig) {
+		cfg.listeners = append(cfg.listeners, listener)
+	}
+}
+
+// engineConfig is the configuration an engine is built from.
+type engineConfig struct {
+	cacheDuration time.Duration
+	timeout       time.Duration
+	checks        []scheduledCheck
+	listeners     []func(ctx context.Context, status AvailabilityStatus)
+	interceptors  []interceptor
+	// startupGracePeriod starts with the creation of the configuration (see
+	// InitChecker).
+	startupGracePeriod time.Duration
+}
+
+// scheduledCheck is a registered check. Checks with a refresh period are
+// executed periodically, all others on every evaluation.
+type scheduledCheck struct {
+	Check
+	refreshPeriod time.Duration
+	initialDelay  time.Duration
+}
+
+func newEngineConfig(options ...Option) engineConfig {
+	cfg := engineConfig{cacheDuration: defaultCacheDuration, timeout: defaultTimeout}
+	for _, option := range options {
+		option(&cfg)
+	}
+	return cfg
+}
+
+// engine evaluates checks. It hides the library that executes the checks
+// (see newHealthEngine) from the rest of the package and from callers.
+type engine interface {
+	// Start starts the background workers of the engine.
+	Start()
+	// Stop stops the engine.
+	Stop()
+	// Check executes the checks, adhering to the deadline of ctx, and
+	// returns their results.
+	Check(ctx context.Context) CheckerResult
+}
+
+// checkState is the outcome of one execution of a check, as seen by
+// interceptors.
+type checkState struct {
+	// Result holds the error of the check (nil if successful).
+	Result error
+	// Status is the availability status of the check.
+	Status AvailabilityStatus
+}
+
+// interceptorFunc executes the check called name.
+type interceptorFunc func(ctx context.Context, name string, state checkState) checkState
+
+// interceptor wraps the execution of every check, like a middleware. It must
+// call next for the check to be executed.
+type interceptor func(next interceptorFunc) interceptorFunc
+
+// chainInterceptors combines interceptors into one, executing them in order.
+func chainInterceptors(interceptors []interceptor) interceptor {
+	return func(next interceptorFunc) interceptorFunc {
+		for i := len(interceptors) - 1; i >= 0; i-- {
+			next = interceptors[i](next)
+		}
+		return next
+	}
+}
@@ -0,0 +1,83 @@
+package healthcheck
+
+import (
+	"context"
+
+	"github.com/alexliesenfeld/health"
+)
+
+// healthEngine is an engine backed by github.com/alexliesenfeld/health. This
+// is the only place the library is used, so it can be replaced without
+// affecting the rest of the package.
+type healthEngine struct {
+	checker health.Checker
+}
+
+func newHealthEngine(cfg engineConfig) engine {
+	options := []health.CheckerOption{
+		health.WithCacheDuration(cfg.cacheDuration),
+		health.WithTimeout(cfg.timeout),
+	}
+	for _, check := range cfg.checks {
+		healthCheck := health.Check{
+			Name:               check.Name,
+			Timeout:            check.Timeout,
+			Check:              check.Check.Check,
+			MaxContiguousFails: uint(check.FailureThreshold),
+		}
+		if check.refreshPeriod > 0 {
+			options = append(options, health.WithPeriodicCheck(check.refreshPeriod, check.initialDelay, healthCheck))
+		} else {
+			options = append(options, health.WithCheck(healthCheck))
+		}
+	}
+	for _, listener := range cfg.listeners {
+		listener := listener
+		options = append(options, health.WithStatusListener(func(ctx context.Context, state health.CheckerState) {
+			listener(ctx, AvailabilityStatus(state.Status))
+		}))
+	}
+	if len(cfg.interceptors) > 0 {
+		options = append(options, health.WithInterceptors(healthInterceptor(chainInterceptors(cfg.interceptors))))
+	}
+	return &healthEngine{checker: health.NewChecker(options...)}
+}
+
+func (e *healthEngine) Start() { e.checker.Start() }
+
+func (e *healthEngine) Stop() { e.checker.Stop() }
+
+func (e *healthEngine) Check(ctx context.Context) CheckerResult {
+	result := e.checker.Check(ctx)
+	converted := CheckerResult{Status: AvailabilityStatus(result.Status)}
+	if result.Details == nil {
+		return converted
+	}
+	converted.Details = make(map[string]CheckResult, len(*result.Details))
+	for name, check := range *result.Details {
+		converted.Details[name] = CheckResult{
+			Status:    AvailabilityStatus(check.Status),
+			Timestamp: check.Timestamp,
+			Error:     check.Error,
+		}
+	}
+	return converted
+}
+
+// healthInterceptor adapts an interceptor to a health.Interceptor. The state
+// tracked by the library is passed through unchanged, except for the result
+// and status set by the interceptor.
+func healthInterceptor(i interceptor) health.Interceptor {
+	return func(next health.InterceptorFunc) health.InterceptorFunc {
+		return func(ctx context.Context, name string, state health.CheckState) health.CheckState {
+			next := i(func(ctx context.Context, name string, _ checkState) checkState {
+				state = next(ctx, name, state)
+				return checkState{Result: state.Result, Status: AvailabilityStatus(state.Status)}
+			})
+			result := next(ctx, name, checkState{Result: state.Result, Status: AvailabilityStatus(state.Status)})
+			state.Result = result.Result
+			state.Status = health.AvailabilityStatus(result.Status)
+			return state
+		}
+	}
+}
@@ -0,0 +1,33 @@
+package healthcheck
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// EnvCheck returns a Check that fails if any of the named environment
+// variables is unset or empty. This makes a misconfigured deployment fail
+// its readiness check instead of its first request.
+func EnvCheck(names ...string) func(ctx context.Context) error {
+	return ConfigKeyCheck(os.LookupEnv, names...)
+}
+
+// ConfigKeyCheck returns a Check that fails if lookup does not find a
+// non-empty value for any of the keys. lookup can wrap any configuration
+// source, e.g. a map or a configuration library.
+func ConfigKeyCheck(lookup func(key string) (string, bool), keys ...string) func(ctx context.Context) error {
+	return func(ctx context.Context) error {
+		var missing []string
+		for _, key := range keys {
+			if value, ok := lookup(key); !ok || value == "" {
+				missing = append(missing, key)
+			}
+		}
+		if len(missing) > 0 {
+			return fmt.Errorf("missing configuration: %s", strings.Join(missing, ", "))
+		}
+		return nil
+	}
+}
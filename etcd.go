@@ -0,0 +1,81 @@
+package healthcheck
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// EtcdMemberStatus is the status of an etcd member, as returned by the
+// Status method of the Maintenance API of go.etcd.io/etcd/client/v3.
+type EtcdMemberStatus struct {
+	// Leader is the member ID of the leader, zero if there is none.
+	Leader uint64
+	// Errors holds alarms and other errors of the member.
+	Errors []string
+}
+
+// EtcdCheck returns a Check that queries the status of every etcd endpoint
+// (e.g., "etcd-0:2379") with status, which usually wraps the Status method
+// of a clientv3.Client:
+//
+//	healthcheck.EtcdCheck(func(ctx context.Context, endpoint string) (healthcheck.EtcdMemberStatus, error) {
+//		resp, err := client.Status(ctx, endpoint)
+//		if err != nil {
+//			return healthcheck.EtcdMemberStatus{}, err
+//		}
+//		return healthcheck.EtcdMemberStatus{Leader: resp.Leader, Errors: resp.Errors}, nil
+//	}, client.Endpoints(), 2*time.Second)
+//
+// It fails when fewer than a quorum of the endpoints are healthy, or when
+// the cluster has no leader. Endpoints reporting errors, such as an active
+// NOSPACE alarm, count as unhealthy.
+func EtcdCheck(status func(ctx context.Context, endpoint string) (EtcdMemberStatus, error), endpoints []string, timeout time.Duration) func(ctx context.Context) error {
+	return func(ctx context.Context) error {
+		ctx, cancel := withOptionalTimeout(ctx, timeout)
+		defer cancel()
+		var mtx sync.Mutex
+		var wg sync.WaitGroup
+		var failures []string
+		leaders := 0
+		for _, endpoint := range endpoints {
+			wg.Add(1)
+			go func(endpoint string) {
+				defer wg.Done()
+				hasLeader, err := etcdHasLeader(ctx, status, endpoint)
+				mtx.Lock()
+				defer mtx.Unlock()
+				if err != nil {
+					failures = append(failures, fmt.Sprintf("%s: %v", endpoint, err))
+				} else if hasLeader {
+					leaders++
+				}
+			}(endpoint)
+		}
+		wg.Wait()
+		quorum := len(endpoints)/2 + 1
+		if healthy := len(endpoints) - len(failures); healthy < quorum {
+			return fmt.Errorf("%d of %d endpoints healthy, quorum is %d: %s",
+				healthy, len(endpoints), quorum, strings.Join(failures, "; "))
+		}
+		if leaders == 0 {
+			return errors.New("cluster has no leader")
+		}
+		return nil
+	}
+}
+
+// etcdHasLeader returns whether the member at endpoint knows a leader.
+func etcdHasLeader(ctx context.Context, status func(ctx context.Context, endpoint string) (EtcdMemberStatus, error), endpoint string) (bool, error) {
+	resp, err := status(ctx, endpoint)
+	if err != nil {
+		return false, err
+	}
+	if len(resp.Errors) > 0 {
+		return false, errors.New(strings.Join(resp.Errors, ", "))
+	}
+	return resp.Leader != 0, nil
+}
@@ -0,0 +1,38 @@
+package healthcheck
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestEtcdCheck(t *testing.T) {
+	healthy := EtcdMemberStatus{Leader: 1}
+	tests := []struct {
+		name     string
+		statuses map[string]EtcdMemberStatus
+		wantErr  string
+	}{
+		{"all healthy", map[string]EtcdMemberStatus{"a": healthy, "b": healthy, "c": healthy}, ""},
+		{"quorum healthy", map[string]EtcdMemberStatus{"a": healthy, "b": healthy}, ""},
+		{"quorum lost", map[string]EtcdMemberStatus{"a": healthy}, "1 of 3 endpoints healthy"},
+		{"alarm", map[string]EtcdMemberStatus{"a": healthy, "b": {Leader: 1, Errors: []string{"NOSPACE"}}}, "b: NOSPACE"},
+		{"no leader", map[string]EtcdMemberStatus{"a": {}, "b": {}, "c": {}}, "no leader"},
+	}
+	for _, test := range tests {
+		status := func(ctx context.Context, endpoint string) (EtcdMemberStatus, error) {
+			if resp, ok := test.statuses[endpoint]; ok {
+				return resp, nil
+			}
+			return EtcdMemberStatus{}, errors.New("connection refused")
+		}
+		err := EtcdCheck(status, []string{"a", "b", "c"}, 0)(context.Background())
+		switch {
+		case test.wantErr == "" && err != nil:
+			t.Errorf("%s: unexpected error %v", test.name, err)
+		case test.wantErr != "" && (err == nil || !strings.Contains(err.Error(), test.wantErr)):
+			t.Errorf("%s: error %v, want %q", test.name, err, test.wantErr)
+		}
+	}
+}
@@ -0,0 +1,33 @@
+package healthcheck
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// ExpiryCheck returns a Check that fails once the credential described by
+// name, such as a third-party API key, license or OAuth refresh token, has
+// expired, and reports it as degraded (see Degraded) while it expires within
+// warnBefore. expiresAt returns the expiry time; a zero time means the
+// credential does not expire.
+func ExpiryCheck(name string, expiresAt func() (time.Time, error), warnBefore time.Duration) func(ctx context.Context) error {
+	return func(ctx context.Context) error {
+		expiry, err := expiresAt()
+		if err != nil {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+		if expiry.IsZero() {
+			return nil
+		}
+		remaining := time.Until(expiry)
+		if remaining <= 0 {
+			return fmt.Errorf("%s expired on %s", name, expiry.Format(time.RFC3339))
+		}
+		if remaining < warnBefore {
+			return Degraded(fmt.Errorf("%s expires on %s (in %s)",
+				name, expiry.Format(time.RFC3339), remaining.Round(time.Minute)))
+		}
+		return nil
+	}
+}
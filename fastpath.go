@@ -0,0 +1,89 @@
+package healthcheck
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Header values shared by all cached responses, so serving a cached response
+// does not allocate them again.
+var (
+	cachedContentType = []string{"application/json; charset=utf-8"}
+	cachedNoCache     = []string{"no-cache"}
+	cachedExpires     = []string{"-1"}
+)
+
+// cachedResponse is a fully serialized checker response.
+type cachedResponse struct {
+	body       []byte
+	statusCode int
+	expiresAt  time.Time
+}
+
+// cachingHandler serves pre-serialized responses of its checker. The current
+// response is swapped atomically, so concurrent requests are served without
+// locking. Once it expires, one request refreshes it while the others are
+// still served the previous response.
+type cachingHandler struct {
+	checker    engine
+	writer     *resultWriter
+	ttl        time.Duration
+	current    atomic.Value // *cachedResponse
+	refreshing int32
+	mtx        sync.Mutex // serializes the initial evaluation
+}
+
+func (h *cachingHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	resp, _ := h.current.Load().(*cachedResponse)
+	switch {
+	case resp == nil:
+		resp = h.initialize(r.Context())
+	case time.Now().After(resp.expiresAt) && atomic.CompareAndSwapInt32(&h.refreshing, 0, 1):
+		resp = h.refresh(r.Context())
+		atomic.StoreInt32(&h.refreshing, 0)
+	}
+	header := w.Header()
+	header["Content-Type"] = cachedContentType
+	header["Cache-Control"] = cachedNoCache
+	header["Pragma"] = cachedNoCache
+	header["Expires"] = cachedExpires
+	w.WriteHeader(resp.statusCode)
+	w.Write(resp.body)
+}
+
+func (h *cachingHandler) initialize(ctx context.Context) *cachedResponse {
+	h.mtx.Lock()
+	defer h.mtx.Unlock()
+	if resp, ok := h.current.Load().(*cachedResponse); ok {
+		return resp
+	}
+	return h.refresh(ctx)
+}
+
+func (h *cachingHandler) refresh(ctx context.Context) *cachedResponse {
+	result := h.checker.Check(ctx)
+	body, err := json.Marshal(h.writer.response(&result))
+	if err != nil {
+		body = []byte(`{"status":"` + string(result.Status) + `"}`)
+	}
+	resp := &cachedResponse{
+		body:       body,
+		statusCode: statusCode(result.Status),
+		expiresAt:  time.Now().Add(h.ttl),
+	}
+	h.current.Store(resp)
+	return resp
+}
+
+// statusCode maps an availability status to the HTTP status code of the
+// response. Only a system that is down or unknown is reported unavailable.
+func statusCode(status AvailabilityStatus) int {
+	if status == StatusDown || status == StatusUnknown {
+		return http.StatusServiceUnavailable
+	}
+	return http.StatusOK
+}
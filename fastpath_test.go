@@ -0,0 +1,62 @@
+package healthcheck
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// discardResponseWriter is a ResponseWriter that does not allocate, so the
+// benchmarks measure the allocations of the handler only.
+type discardResponseWriter struct {
+	header http.Header
+}
+
+func (w *discardResponseWriter) Header() http.Header { return w.header }
+
+func (w *discardResponseWriter) Write(b []byte) (int, error) { return len(b), nil }
+
+func (w *discardResponseWriter) WriteHeader(statusCode int) {}
+
+func benchmarkHandler(b *testing.B, ttl time.Duration) {
+	config := InitChecker()
+	for _, name := range []string{"database", "cache", "queue"} {
+		config.MustAdd(WithCheck(Check{Name: name, Check: func(ctx context.Context) error { return nil }}))
+	}
+	config.AddCheck(WithCacheDuration(time.Hour))
+	if ttl > 0 {
+		config.EnableCachedResponses(ttl)
+	}
+	handler := config.GetCheckerHandler()
+	w := &discardResponseWriter{header: http.Header{}}
+	r := httptest.NewRequest(http.MethodGet, "/health", nil)
+	handler(w, r)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		handler(w, r)
+	}
+}
+
+func BenchmarkHandler(b *testing.B) {
+	benchmarkHandler(b, 0)
+}
+
+func BenchmarkCachedHandler(b *testing.B) {
+	benchmarkHandler(b, time.Hour)
+}
+
+func TestCachedHandlerDoesNotAllocate(t *testing.T) {
+	config := InitChecker()
+	config.MustAdd(WithCheck(Check{Name: "database", Check: func(ctx context.Context) error { return nil }}))
+	config.EnableCachedResponses(time.Hour)
+	handler := config.GetCheckerHandler()
+	w := &discardResponseWriter{header: http.Header{}}
+	r := httptest.NewRequest(http.MethodGet, "/health", nil)
+	handler(w, r)
+	if allocs := testing.AllocsPerRun(100, func() { handler(w, r) }); allocs > 0 {
+		t.Errorf("cached handler allocated %v times per request, want 0", allocs)
+	}
+}
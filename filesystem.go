@@ -0,0 +1,72 @@
+package healthcheck
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"time"
+)
+
+// diskProbeSize is the size of the file written by DiskLatencyCheck.
+const diskProbeSize = 4096
+
+// DiskLatencyCheck returns a Check that writes, fsyncs and reads back a small
+// probe file in dir and fails if this takes longer than threshold. This
+// detects degraded volumes and failing disks that still accept writes.
+func DiskLatencyCheck(dir string, threshold time.Duration) func(ctx context.Context) error {
+	payload := bytes.Repeat([]byte("healthcheck"), diskProbeSize/len("healthcheck")+1)[:diskProbeSize]
+	return func(ctx context.Context) error {
+		start := time.Now()
+		f, err := os.CreateTemp(dir, ".healthcheck-*")
+		if err != nil {
+			return err
+		}
+		defer os.Remove(f.Name())
+		if _, err := f.Write(payload); err != nil {
+			f.Close()
+			return err
+		}
+		if err := f.Sync(); err != nil {
+			f.Close()
+			return err
+		}
+		if err := f.Close(); err != nil {
+			return err
+		}
+		content, err := os.ReadFile(f.Name())
+		if err != nil {
+			return err
+		}
+		if !bytes.Equal(content, payload) {
+			return fmt.Errorf("probe file content mismatch")
+		}
+		if latency := time.Since(start); latency > threshold {
+			return fmt.Errorf("disk probe took %s > %s", latency, threshold)
+		}
+		return nil
+	}
+}
+
+// FileWriteCheck returns a Check that creates, writes, fsyncs and removes a
+// small file in dir. This detects read-only remounts and permission changes
+// that go unnoticed by read-only access.
+func FileWriteCheck(dir string) func(ctx context.Context) error {
+	return func(ctx context.Context) error {
+		f, err := os.CreateTemp(dir, ".healthcheck-*")
+		if err != nil {
+			return err
+		}
+		_, err = f.Write([]byte("healthcheck"))
+		if err == nil {
+			err = f.Sync()
+		}
+		if closeErr := f.Close(); err == nil {
+			err = closeErr
+		}
+		if removeErr := os.Remove(f.Name()); err == nil {
+			err = removeErr
+		}
+		return err
+	}
+}
@@ -0,0 +1,345 @@
+package healthcheck
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/textproto"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/crypto/ssh"
+)
+
+// FTPCheck returns a Check that logs into the FTP server at addr and lists
+// the directory dir within the specified timeout. An empty user logs in
+// anonymously.
+func FTPCheck(addr, user, password, dir string, timeout time.Duration) func(ctx context.Context) error {
+	if user == "" {
+		user, password = "anonymous", "anonymous"
+	}
+	return func(ctx context.Context) error {
+		ctx, cancel := withOptionalTimeout(ctx, timeout)
+		defer cancel()
+		dialer := net.Dialer{}
+		conn, err := dialer.DialContext(ctx, "tcp", addr)
+		if err != nil {
+			return err
+		}
+		defer conn.Close()
+		deadline, _ := ctx.Deadline()
+		conn.SetDeadline(deadline)
+
+		text := textproto.NewConn(conn)
+		if _, _, err := text.ReadResponse(220); err != nil {
+			return err
+		}
+		code, _, err := ftpCommand(text, "USER %s", user)
+		if err != nil {
+			return err
+		}
+		if code == 331 {
+			if code, _, err = ftpCommand(text, "PASS %s", password); err != nil {
+				return err
+			}
+		}
+		if code != 230 {
+			return fmt.Errorf("login failed with code %d", code)
+		}
+		port, err := ftpPassivePort(text)
+		if err != nil {
+			return err
+		}
+		host, _, err := net.SplitHostPort(addr)
+		if err != nil {
+			return err
+		}
+		data, err := dialer.DialContext(ctx, "tcp", net.JoinHostPort(host, strconv.Itoa(port)))
+		if err != nil {
+			return err
+		}
+		defer data.Close()
+		data.SetDeadline(deadline)
+		if code, _, err = ftpCommand(text, "LIST %s", dir); err != nil {
+			return err
+		}
+		if code != 125 && code != 150 {
+			return fmt.Errorf("listing %s failed with code %d", dir, code)
+		}
+		if _, err := io.Copy(io.Discard, data); err != nil {
+			return err
+		}
+		if _, _, err := text.ReadResponse(226); err != nil {
+			return err
+		}
+		ftpCommand(text, "QUIT")
+		return nil
+	}
+}
+
+func ftpCommand(text *textproto.Conn, format string, args ...interface{}) (int, string, error) {
+	if err := text.PrintfLine(format, args...); err != nil {
+		return 0, "", err
+	}
+	code, msg, err := text.ReadResponse(0)
+	if _, ok := err.(*textproto.Error); ok {
+		// Unexpected response codes are evaluated by the caller.
+		err = nil
+	}
+	return code, msg, err
+}
+
+// ftpPassivePort enters passive mode and returns the data port. It prefers
+// EPSV and falls back to PASV for servers that refuse it. Like with EPSV,
+// the data connection goes to the host of the control connection rather
+// than to the address in the PASV response, which is often wrong behind NAT.
+func ftpPassivePort(text *textproto.Conn) (int, error) {
+	code, msg, err := ftpCommand(text, "EPSV")
+	if err != nil {
+		return 0, err
+	}
+	if code == 229 {
+		return parseEPSVPort(msg)
+	}
+	if code, msg, err = ftpCommand(text, "PASV"); err != nil {
+		return 0, err
+	}
+	if code != 227 {
+		return 0, fmt.Errorf("passive mode refused with code %d", code)
+	}
+	return parsePASVPort(msg)
+}
+
+// parsePASVPort extracts the port from a PASV response such as
+// "Entering Passive Mode (192,168,1,2,25,46)".
+func parsePASVPort(msg string) (int, error) {
+	start, end := strings.Index(msg, "("), strings.LastIndex(msg, ")")
+	if start < 0 || end < start {
+		return 0, fmt.Errorf("invalid PASV response %q", msg)
+	}
+	fields := strings.Split(msg[start+1:end], ",")
+	if len(fields) != 6 {
+		return 0, fmt.Errorf("invalid PASV response %q", msg)
+	}
+	var port int
+	for _, field := range fields[4:] {
+		v, err := strconv.ParseUint(strings.TrimSpace(field), 10, 8)
+		if err != nil {
+			return 0, fmt.Errorf("invalid PASV response %q", msg)
+		}
+		port = port<<8 | int(v)
+	}
+	return port, nil
+}
+
+// parseEPSVPort extracts the port from an EPSV response such as
+// "Entering Extended Passive Mode (|||6446|)".
+func parseEPSVPort(msg string) (int, error) {
+	start, end := strings.Index(msg, "(|||"), strings.LastIndex(msg, "|)")
+	if start < 0 || end < start+4 {
+		return 0, fmt.Errorf("invalid EPSV response %q", msg)
+	}
+	return strconv.Atoi(msg[start+4 : end])
+}
+
+// SFTPCheck returns a Check that connects to the SSH server at addr using the
+// provided client configuration, opens an SFTP session and lists the
+// directory dir within the specified timeout. The check fails if config is
+// nil, since an SFTP session requires authentication.
+func SFTPCheck(addr string, config *ssh.ClientConfig, dir string, timeout time.Duration) func(ctx context.Context) error {
+	return func(ctx context.Context) error {
+		if config == nil {
+			return errors.New("no SSH client configuration")
+		}
+		ctx, cancel := withOptionalTimeout(ctx, timeout)
+		defer cancel()
+		client, err := dialSSH(ctx, addr, config)
+		if err != nil {
+			return err
+		}
+		defer client.Close()
+		return sftpListDir(client, dir)
+	}
+}
+
+// SSHCheck returns a Check that completes an SSH handshake with the server
+// at addr within the specified timeout. If config has authentication
+// methods, the check also fails unless authentication succeeds; otherwise
+// it only verifies the key exchange and the host key. A nil config accepts
+// any host key. See SFTPCheck for checking an SFTP session.
+func SSHCheck(addr string, config *ssh.ClientConfig, timeout time.Duration) func(ctx context.Context) error {
+	return func(ctx context.Context) error {
+		ctx, cancel := withOptionalTimeout(ctx, timeout)
+		defer cancel()
+		handshakeOnly := config == nil || len(config.Auth) == 0
+		clientConfig := &ssh.ClientConfig{HostKeyCallback: ssh.InsecureIgnoreHostKey()}
+		if config != nil {
+			c := *config
+			clientConfig = &c
+		}
+		verified := false
+		hostKeyCallback := clientConfig.HostKeyCallback
+		clientConfig.HostKeyCallback = func(hostname string, remote net.Addr, key ssh.PublicKey) error {
+			if hostKeyCallback == nil {
+				return errors.New("no host key callback configured")
+			}
+			if err := hostKeyCallback(hostname, remote, key); err != nil {
+				return err
+			}
+			verified = true
+			return nil
+		}
+		client, err := dialSSH(ctx, addr, clientConfig)
+		if err != nil {
+			// Without authentication methods, the server rejects the
+			// client after the key exchange.
+			if handshakeOnly && verified {
+				return nil
+			}
+			return err
+		}
+		return client.Close()
+	}
+}
+
+// dialSSH establishes an SSH connection that is closed when ctx is done.
+func dialSSH(ctx context.Context, addr string, config *ssh.ClientConfig) (*ssh.Client, error) {
+	dialer := net.Dialer{}
+	conn, err := dialer.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+	c, chans, reqs, err := ssh.NewClientConn(conn, addr, config)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	return ssh.NewClient(c, chans, reqs), nil
+}
+
+// SFTP packet types (draft-ietf-secsh-filexfer-02).
+const (
+	sftpInit     = 1
+	sftpVersion  = 2
+	sftpClose    = 4
+	sftpOpenDir  = 11
+	sftpReadDir  = 12
+	sftpStatus   = 101
+	sftpHandle   = 102
+	sftpName     = 104
+	sftpStatusOK = 0
+	sftpEOF      = 1
+)
+
+// sftpListDir opens an SFTP session on client and reads the first batch of
+// entries of dir.
+func sftpListDir(client *ssh.Client, dir string) error {
+	session, err := client.NewSession()
+	if err != nil {
+		return err
+	}
+	defer session.Close()
+	w, err := session.StdinPipe()
+	if err != nil {
+		return err
+	}
+	r, err := session.StdoutPipe()
+	if err != nil {
+		return err
+	}
+	if err := session.RequestSubsystem("sftp"); err != nil {
+		return err
+	}
+
+	if err := writeSFTPPacket(w, sftpInit, appendUint32(nil, 3)); err != nil {
+		return err
+	}
+	if typ, _, err := readSFTPPacket(r); err != nil {
+		return err
+	} else if typ != sftpVersion {
+		return fmt.Errorf("unexpected SFTP packet type %d", typ)
+	}
+
+	if err := writeSFTPPacket(w, sftpOpenDir, appendSFTPString(appendUint32(nil, 1), dir)); err != nil {
+		return err
+	}
+	typ, payload, err := readSFTPPacket(r)
+	if err != nil {
+		return err
+	}
+	if typ != sftpHandle {
+		if err := sftpError(typ, payload, "opening "+dir); err != nil {
+			return err
+		}
+		return fmt.Errorf("opening %s: no handle returned", dir)
+	}
+	handle := payload[4:]
+
+	if err := writeSFTPPacket(w, sftpReadDir, append(appendUint32(nil, 2), handle...)); err != nil {
+		return err
+	}
+	if typ, payload, err = readSFTPPacket(r); err != nil {
+		return err
+	}
+	if typ != sftpName {
+		if err := sftpError(typ, payload, "listing "+dir); err != nil {
+			return err
+		}
+	}
+	return writeSFTPPacket(w, sftpClose, append(appendUint32(nil, 3), handle...))
+}
+
+func appendUint32(b []byte, v uint32) []byte {
+	return append(b, byte(v>>24), byte(v>>16), byte(v>>8), byte(v))
+}
+
+func appendSFTPString(b []byte, s string) []byte {
+	b = appendUint32(b, uint32(len(s)))
+	return append(b, s...)
+}
+
+func writeSFTPPacket(w io.Writer, typ byte, payload []byte) error {
+	packet := appendUint32(nil, uint32(len(payload)+1))
+	packet = append(packet, typ)
+	_, err := w.Write(append(packet, payload...))
+	return err
+}
+
+func readSFTPPacket(r io.Reader) (byte, []byte, error) {
+	var header [5]byte
+	if _, err := io.ReadFull(r, header[:]); err != nil {
+		return 0, nil, err
+	}
+	length := binary.BigEndian.Uint32(header[:4])
+	if length < 1 || length > 256*1024 {
+		return 0, nil, fmt.Errorf("invalid SFTP packet length %d", length)
+	}
+	payload := make([]byte, length-1)
+	if _, err := io.ReadFull(r, payload); err != nil {
+		return 0, nil, err
+	}
+	if len(payload) < 4 {
+		return 0, nil, fmt.Errorf("short SFTP packet")
+	}
+	return header[4], payload, nil
+}
+
+// sftpError converts an SFTP status packet into an error. EOF while listing
+// an empty directory is not an error.
+func sftpError(typ byte, payload []byte, op string) error {
+	if typ != sftpStatus || len(payload) < 8 {
+		return fmt.Errorf("%s: unexpected SFTP packet type %d", op, typ)
+	}
+	code := binary.BigEndian.Uint32(payload[4:8])
+	if code == sftpStatusOK || code == sftpEOF {
+		return nil
+	}
+	return fmt.Errorf("%s: SFTP status %d", op, code)
+}
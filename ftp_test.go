@@ -0,0 +1,108 @@
+package healthcheck
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"net"
+	"strings"
+	"testing"
+	"time"
+)
+
+// serveFTP accepts one control connection on ln and answers the commands of
+// FTPCheck. EPSV is answered with epsvCode unless it is 229.
+func serveFTP(t *testing.T, ln net.Listener, epsvCode int) {
+	conn, err := ln.Accept()
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+	data, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	defer data.Close()
+	port := data.Addr().(*net.TCPAddr).Port
+
+	fmt.Fprintf(conn, "220 ready\r\n")
+	r := bufio.NewReader(conn)
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return
+		}
+		command, _, _ := strings.Cut(strings.TrimSpace(line), " ")
+		switch command {
+		case "USER":
+			fmt.Fprintf(conn, "331 password required\r\n")
+		case "PASS":
+			fmt.Fprintf(conn, "230 logged in\r\n")
+		case "EPSV":
+			if epsvCode == 229 {
+				fmt.Fprintf(conn, "229 Entering Extended Passive Mode (|||%d|)\r\n", port)
+			} else {
+				fmt.Fprintf(conn, "%d EPSV not supported\r\n", epsvCode)
+			}
+		case "PASV":
+			// The address is deliberately wrong, as behind NAT.
+			fmt.Fprintf(conn, "227 Entering Passive Mode (10,0,0,1,%d,%d)\r\n", port>>8, port&0xff)
+		case "LIST":
+			fmt.Fprintf(conn, "150 listing\r\n")
+			if c, err := data.Accept(); err == nil {
+				fmt.Fprintf(c, "file.txt\r\n")
+				c.Close()
+			}
+			fmt.Fprintf(conn, "226 done\r\n")
+		case "QUIT":
+			fmt.Fprintf(conn, "221 bye\r\n")
+			return
+		default:
+			fmt.Fprintf(conn, "500 unknown command\r\n")
+		}
+	}
+}
+
+func TestFTPCheck(t *testing.T) {
+	for _, epsvCode := range []int{229, 522, 500} {
+		t.Run(fmt.Sprint(epsvCode), func(t *testing.T) {
+			ln, err := net.Listen("tcp", "127.0.0.1:0")
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer ln.Close()
+			go serveFTP(t, ln, epsvCode)
+			if err := FTPCheck(ln.Addr().String(), "", "", "/", time.Second)(context.Background()); err != nil {
+				t.Fatal(err)
+			}
+		})
+	}
+}
+
+func TestParsePASVPort(t *testing.T) {
+	tests := []struct {
+		msg  string
+		port int
+		ok   bool
+	}{
+		{"Entering Passive Mode (192,168,1,2,25,46)", 25<<8 | 46, true},
+		{"Entering Passive Mode (192,168,1,2,25,46).", 25<<8 | 46, true},
+		{"Entering Passive Mode 192,168,1,2,25,46", 0, false},
+		{"Entering Passive Mode (192,168,1,2,25)", 0, false},
+		{"Entering Passive Mode (192,168,1,2,256,46)", 0, false},
+	}
+	for _, tt := range tests {
+		port, err := parsePASVPort(tt.msg)
+		if (err == nil) != tt.ok || port != tt.port {
+			t.Errorf("parsePASVPort(%q) = %d, %v", tt.msg, port, err)
+		}
+	}
+}
+
+func TestSFTPCheckWithoutConfig(t *testing.T) {
+	err := SFTPCheck("127.0.0.1:22", nil, "/", time.Second)(context.Background())
+	if err == nil || err.Error() != "no SSH client configuration" {
+		t.Errorf("error %v, want no SSH client configuration", err)
+	}
+}
@@ -0,0 +1,37 @@
+// Package gcs provides health checks for Google Cloud Storage, backed by the
+// cloud.google.com/go/storage client. It is a separate module, so the core
+// healthcheck package does not depend on the Google Cloud SDK.
+package gcs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"cloud.google.com/go/storage"
+	"google.golang.org/api/iterator"
+)
+
+// BucketCheck returns a Check that lists at most one object of bucket with
+// client. This verifies that the API is reachable and that the credentials
+// of the client are valid and grant access to the bucket. A zero timeout
+// leaves the deadline of the check context.
+func BucketCheck(client *storage.Client, bucket string, timeout time.Duration) func(ctx context.Context) error {
+	return func(ctx context.Context) error {
+		if timeout > 0 {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, timeout)
+			defer cancel()
+		}
+		if client == nil {
+			return errors.New("client is nil")
+		}
+		objects := client.Bucket(bucket).Objects(ctx, &storage.Query{})
+		objects.PageInfo().MaxSize = 1
+		if _, err := objects.Next(); err != nil && !errors.Is(err, iterator.Done) {
+			return fmt.Errorf("bucket %s: %w", bucket, err)
+		}
+		return nil
+	}
+}
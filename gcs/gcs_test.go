@@ -0,0 +1,41 @@
+package gcs
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"cloud.google.com/go/storage"
+	"google.golang.org/api/option"
+)
+
+func TestBucketCheck(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case strings.Contains(r.URL.Path, "/b/healthy/o"):
+			w.Header().Set("Content-Type", "application/json")
+			w.Write([]byte(`{"kind":"storage#objects"}`))
+		default:
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusForbidden)
+			w.Write([]byte(`{"error":{"code":403,"message":"access denied"}}`))
+		}
+	}))
+	defer server.Close()
+	client, err := storage.NewClient(context.Background(),
+		option.WithEndpoint(server.URL+"/storage/v1/"), option.WithoutAuthentication())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+
+	if err := BucketCheck(client, "healthy", time.Second)(context.Background()); err != nil {
+		t.Errorf("healthy bucket: %v", err)
+	}
+	if err := BucketCheck(client, "forbidden", 0)(context.Background()); err == nil {
+		t.Error("forbidden bucket: no error")
+	}
+}
@@ -2,4 +2,18 @@ module github.com/andiwork/go-healthcheck
 
 go 1.18
 
-require github.com/alexliesenfeld/health v0.6.0
+require (
+	github.com/alexliesenfeld/health v0.6.0
+	github.com/gocql/gocql v1.6.0
+	golang.org/x/crypto v0.24.0
+	golang.org/x/net v0.26.0
+	gopkg.in/yaml.v3 v3.0.1
+)
+
+require (
+	github.com/golang/snappy v0.0.3 // indirect
+	github.com/hailocab/go-hostpool v0.0.0-20160125115350-e80d13ce29ed // indirect
+	golang.org/x/sys v0.21.0 // indirect
+	golang.org/x/text v0.16.0 // indirect
+	gopkg.in/inf.v0 v0.9.1 // indirect
+)
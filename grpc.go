@@ -0,0 +1,171 @@
+package healthcheck
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"golang.org/x/net/http2"
+)
+
+// GRPCOptions configures how the gRPC checks connect to their target.
+type GRPCOptions struct {
+	// TLS is the TLS configuration used to connect to the target. The
+	// connection is plaintext (h2c) if TLS is nil.
+	TLS *tls.Config
+	// Authority overrides the :authority pseudo-header, which defaults to
+	// the target address.
+	Authority string
+	// Metadata holds additional request metadata, such as an authorization
+	// header.
+	Metadata map[string]string
+}
+
+// grpcStatusError is the error returned when a gRPC call completes with a
+// status other than OK.
+type grpcStatusError struct {
+	code    int
+	message string
+}
+
+func (e *grpcStatusError) Error() string {
+	return fmt.Sprintf("rpc error: code = %d desc = %s", e.code, e.message)
+}
+
+const (
+	grpcNotFound      = 5
+	grpcUnimplemented = 12
+)
+
+// grpcMaxMessageSize limits the size of received messages, like the default
+// receive limit of grpc-go.
+const grpcMaxMessageSize = 4 << 20
+
+// grpcConn performs gRPC calls over HTTP/2 without generated stubs.
+type grpcConn struct {
+	client  *http.Client
+	baseURL string
+	options GRPCOptions
+}
+
+func newGRPCConn(addr string, options GRPCOptions) *grpcConn {
+	transport := &http2.Transport{TLSClientConfig: options.TLS}
+	scheme := "https"
+	if options.TLS == nil {
+		scheme = "http"
+		transport = h2cTransport()
+	}
+	return &grpcConn{
+		client:  &http.Client{Transport: transport},
+		baseURL: scheme + "://" + addr,
+		options: options,
+	}
+}
+
+// call invokes method (e.g., "/grpc.health.v1.Health/Check") with the
+// encoded request messages and returns the encoded response messages.
+// Streaming methods are supported as long as the server answers after the
+// client has sent all its messages.
+func (c *grpcConn) call(ctx context.Context, method string, requests ...[]byte) ([][]byte, error) {
+	var body bytes.Buffer
+	for _, msg := range requests {
+		var header [5]byte
+		binary.BigEndian.PutUint32(header[1:], uint32(len(msg)))
+		body.Write(header[:])
+		body.Write(msg)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.baseURL+method, &body)
+	if err != nil {
+		return nil, err
+	}
+	if c.options.Authority != "" {
+		req.Host = c.options.Authority
+	}
+	req.Header.Set("Content-Type", "application/grpc")
+	req.Header.Set("TE", "trailers")
+	for key, value := range c.options.Metadata {
+		req.Header.Set(key, value)
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		timeout := time.Until(deadline).Milliseconds()
+		if timeout < 1 {
+			timeout = 1
+		}
+		req.Header.Set("Grpc-Timeout", strconv.FormatInt(timeout, 10)+"m")
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("returned HTTP status %d", resp.StatusCode)
+	}
+
+	var responses [][]byte
+	for {
+		var header [5]byte
+		if _, err := io.ReadFull(resp.Body, header[:]); err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, err
+		}
+		if header[0] != 0 {
+			return nil, fmt.Errorf("compressed gRPC messages are not supported")
+		}
+		size := binary.BigEndian.Uint32(header[1:])
+		if size > grpcMaxMessageSize {
+			return nil, fmt.Errorf("gRPC message too large: %d bytes", size)
+		}
+		msg := make([]byte, size)
+		if _, err := io.ReadFull(resp.Body, msg); err != nil {
+			return nil, err
+		}
+		responses = append(responses, msg)
+	}
+	return responses, grpcStatus(resp)
+}
+
+// grpcStatus returns the status of a completed call, which is sent in the
+// trailers or, for responses without messages, in the headers.
+func grpcStatus(resp *http.Response) error {
+	status, message := resp.Trailer.Get("Grpc-Status"), resp.Trailer.Get("Grpc-Message")
+	if status == "" {
+		status, message = resp.Header.Get("Grpc-Status"), resp.Header.Get("Grpc-Message")
+	}
+	if status == "" {
+		return fmt.Errorf("missing gRPC status")
+	}
+	code, err := strconv.Atoi(status)
+	if err != nil {
+		return fmt.Errorf("invalid gRPC status %q", status)
+	}
+	if code == 0 {
+		return nil
+	}
+	if unescaped, err := url.PathUnescape(message); err == nil {
+		message = unescaped
+	}
+	return &grpcStatusError{code: code, message: message}
+}
+
+// h2cTransport returns a transport that sends plaintext HTTP/2 requests with
+// prior knowledge (h2c) for http URLs.
+func h2cTransport() *http2.Transport {
+	return &http2.Transport{
+		AllowHTTP: true,
+		DialTLSContext: func(ctx context.Context, network, addr string, _ *tls.Config) (net.Conn, error) {
+			var dialer net.Dialer
+			return dialer.DialContext(ctx, network, addr)
+		},
+	}
+}
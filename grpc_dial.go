@@ -0,0 +1,52 @@
+package healthcheck
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"time"
+
+	"golang.org/x/net/http2"
+)
+
+// GRPCDialCheck returns a Check that establishes an HTTP/2 connection to the
+// gRPC server at addr, as a gRPC client does before its first call, and
+// verifies it with a PING frame. It suits servers that do not implement
+// grpc.health.v1. With options.TLS the server must negotiate "h2" via ALPN;
+// options.Authority overrides the TLS server name. options.Metadata is not
+// used as no call is made.
+func GRPCDialCheck(addr string, options GRPCOptions, timeout time.Duration) func(ctx context.Context) error {
+	transport := &http2.Transport{}
+	return func(ctx context.Context) error {
+		ctx, cancel := withOptionalTimeout(ctx, timeout)
+		defer cancel()
+		config := options.TLS
+		if config != nil {
+			config = config.Clone()
+			config.NextProtos = []string{http2.NextProtoTLS}
+			if config.ServerName == "" {
+				config.ServerName = options.Authority
+			}
+			if config.ServerName == "" {
+				config.ServerName, _, _ = net.SplitHostPort(addr)
+			}
+		}
+		conn, err := dialTCP(ctx, addr, config)
+		if err != nil {
+			return err
+		}
+		defer conn.Close()
+		if tlsConn, ok := conn.(*tls.Conn); ok {
+			if protocol := tlsConn.ConnectionState().NegotiatedProtocol; protocol != http2.NextProtoTLS {
+				return fmt.Errorf("server negotiated %q instead of %q", protocol, http2.NextProtoTLS)
+			}
+		}
+		cc, err := transport.NewClientConn(conn)
+		if err != nil {
+			return err
+		}
+		defer cc.Close()
+		return cc.Ping(ctx)
+	}
+}
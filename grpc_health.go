@@ -0,0 +1,62 @@
+package healthcheck
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// Serving statuses of the gRPC health checking protocol.
+var grpcServingStatuses = map[uint64]string{
+	0: "UNKNOWN",
+	1: "SERVING",
+	2: "NOT_SERVING",
+	3: "SERVICE_UNKNOWN",
+}
+
+// GRPCHealthCheck returns a Check that calls grpc.health.v1.Health/Check on
+// the server at addr for service and fails unless the service is SERVING.
+// An empty service queries the overall health of the server. timeout bounds
+// the RPC and is propagated to the server as grpc-timeout.
+func GRPCHealthCheck(addr, service string, options GRPCOptions, timeout time.Duration) func(ctx context.Context) error {
+	conn := newGRPCConn(addr, options)
+	return func(ctx context.Context) error {
+		ctx, cancel := withOptionalTimeout(ctx, timeout)
+		defer cancel()
+		return grpcHealth(ctx, conn, service)
+	}
+}
+
+// grpcHealth calls grpc.health.v1.Health/Check for service and fails unless
+// the service is SERVING.
+func grpcHealth(ctx context.Context, conn *grpcConn, service string) error {
+	var request []byte
+	if service != "" {
+		request = appendProtoString(nil, 1, service)
+	}
+	responses, err := conn.call(ctx, "/grpc.health.v1.Health/Check", request)
+	if err != nil {
+		return err
+	}
+	if len(responses) != 1 {
+		return fmt.Errorf("expected one response, got %d", len(responses))
+	}
+	fields, err := parseProto(responses[0])
+	if err != nil {
+		return err
+	}
+	var status uint64
+	for _, field := range fields {
+		if field.number == 1 {
+			status = field.varint
+		}
+	}
+	if status != 1 {
+		name, ok := grpcServingStatuses[status]
+		if !ok {
+			name = fmt.Sprint(status)
+		}
+		return fmt.Errorf("service %q is %s", service, name)
+	}
+	return nil
+}
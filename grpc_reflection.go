@@ -0,0 +1,170 @@
+package healthcheck
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// GRPCReflectionCheck returns a Check that uses gRPC server reflection to
+// verify the server at addr exposes the expected services. expected maps
+// fully qualified service names (e.g., "helloworld.Greeter") to the methods
+// that must be present; a service without methods only needs to be
+// registered. This catches partial deployments where the port is open but a
+// service is not registered.
+func GRPCReflectionCheck(addr string, expected map[string][]string, options GRPCOptions, timeout time.Duration) func(ctx context.Context) error {
+	conn := newGRPCConn(addr, options)
+	services := make([]string, 0, len(expected))
+	for service := range expected {
+		services = append(services, service)
+	}
+	sort.Strings(services)
+	return func(ctx context.Context) error {
+		ctx, cancel := withOptionalTimeout(ctx, timeout)
+		defer cancel()
+		requests := [][]byte{appendProtoString(nil, 7, "*")} // list_services
+		for _, service := range services {
+			if len(expected[service]) > 0 {
+				requests = append(requests, appendProtoString(nil, 4, service)) // file_containing_symbol
+			}
+		}
+		responses, err := conn.call(ctx, "/grpc.reflection.v1.ServerReflection/ServerReflectionInfo", requests...)
+		var status *grpcStatusError
+		if errors.As(err, &status) && status.code == grpcUnimplemented {
+			responses, err = conn.call(ctx, "/grpc.reflection.v1alpha.ServerReflection/ServerReflectionInfo", requests...)
+		}
+		if err != nil {
+			return err
+		}
+		registered, methods, err := parseReflectionResponses(responses)
+		if err != nil {
+			return err
+		}
+		var missing []string
+		for _, service := range services {
+			if !registered[service] {
+				missing = append(missing, service)
+				continue
+			}
+			for _, method := range expected[service] {
+				if !methods[service+"/"+method] {
+					missing = append(missing, service+"/"+method)
+				}
+			}
+		}
+		if len(missing) > 0 {
+			return fmt.Errorf("not registered: %s", strings.Join(missing, ", "))
+		}
+		return nil
+	}
+}
+
+// parseReflectionResponses returns the registered services and the methods
+// ("service/method") found in the file descriptors of the responses.
+func parseReflectionResponses(responses [][]byte) (map[string]bool, map[string]bool, error) {
+	services, methods := map[string]bool{}, map[string]bool{}
+	for _, response := range responses {
+		fields, err := parseProto(response)
+		if err != nil {
+			return nil, nil, err
+		}
+		for _, field := range fields {
+			switch field.number {
+			case 4: // file_descriptor_response
+				if err := parseFileDescriptorResponse(field.bytes, methods); err != nil {
+					return nil, nil, err
+				}
+			case 6: // list_services_response
+				list, err := parseProto(field.bytes)
+				if err != nil {
+					return nil, nil, err
+				}
+				for _, service := range list {
+					name, err := protoStringField(service.bytes, 1)
+					if err != nil {
+						return nil, nil, err
+					}
+					services[name] = true
+				}
+			case 7: // error_response
+				message, err := protoStringField(field.bytes, 2)
+				if err != nil {
+					return nil, nil, err
+				}
+				return nil, nil, fmt.Errorf("reflection error: %s", message)
+			}
+		}
+	}
+	return services, methods, nil
+}
+
+func parseFileDescriptorResponse(b []byte, methods map[string]bool) error {
+	files, err := parseProto(b)
+	if err != nil {
+		return err
+	}
+	for _, file := range files {
+		if file.number != 1 {
+			continue
+		}
+		descriptor, err := parseProto(file.bytes)
+		if err != nil {
+			return err
+		}
+		var pkg string
+		var services [][]byte
+		for _, field := range descriptor {
+			switch field.number {
+			case 2:
+				pkg = string(field.bytes)
+			case 6:
+				services = append(services, field.bytes)
+			}
+		}
+		for _, service := range services {
+			fields, err := parseProto(service)
+			if err != nil {
+				return err
+			}
+			var name string
+			var names []string
+			for _, field := range fields {
+				switch field.number {
+				case 1:
+					name = string(field.bytes)
+				case 2:
+					method, err := protoStringField(field.bytes, 1)
+					if err != nil {
+						return err
+					}
+					names = append(names, method)
+				}
+			}
+			if pkg != "" {
+				name = pkg + "." + name
+			}
+			for _, method := range names {
+				methods[name+"/"+method] = true
+			}
+		}
+	}
+	return nil
+}
+
+// protoStringField returns the first string field with the given number of
+// the encoded message b.
+func protoStringField(b []byte, number int) (string, error) {
+	fields, err := parseProto(b)
+	if err != nil {
+		return "", err
+	}
+	for _, field := range fields {
+		if field.number == number {
+			return string(field.bytes), nil
+		}
+	}
+	return "", nil
+}
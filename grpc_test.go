@@ -0,0 +1,189 @@
+package healthcheck
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"golang.org/x/net/http2"
+	"golang.org/x/net/http2/h2c"
+)
+
+// grpcFrame encodes msg as a length-prefixed gRPC message.
+func grpcFrame(compressed bool, msg []byte) []byte {
+	frame := make([]byte, 5, 5+len(msg))
+	if compressed {
+		frame[0] = 1
+	}
+	binary.BigEndian.PutUint32(frame[1:], uint32(len(msg)))
+	return append(frame, msg...)
+}
+
+// newGRPCTestServer serves handler over plaintext HTTP/2 (h2c).
+func newGRPCTestServer(t *testing.T, handler http.HandlerFunc) *grpcConn {
+	server := httptest.NewServer(h2c.NewHandler(handler, &http2.Server{}))
+	t.Cleanup(server.Close)
+	return newGRPCConn(strings.TrimPrefix(server.URL, "http://"), GRPCOptions{
+		Authority: "service.example",
+		Metadata:  map[string]string{"Authorization": "Bearer token"},
+	})
+}
+
+func TestGRPCConnCall(t *testing.T) {
+	tests := []struct {
+		name      string
+		handler   http.HandlerFunc
+		responses []string
+		code      int // expected gRPC status code of a grpcStatusError
+		message   string
+		wantErr   string // expected substring of any other error
+	}{
+		{
+			name: "ok with trailers",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/grpc")
+				w.Write(grpcFrame(false, []byte("one")))
+				w.Write(grpcFrame(false, []byte("two")))
+				w.Header().Set(http.TrailerPrefix+"Grpc-Status", "0")
+			},
+			responses: []string{"one", "two"},
+		},
+		{
+			name: "error in trailers",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/grpc")
+				w.Write(grpcFrame(false, []byte("partial")))
+				w.Header().Set(http.TrailerPrefix+"Grpc-Status", "14")
+				w.Header().Set(http.TrailerPrefix+"Grpc-Message", "backend%20unavailable")
+			},
+			code:    14,
+			message: "backend unavailable",
+		},
+		{
+			name: "trailers only",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/grpc")
+				w.Header().Set("Grpc-Status", "12")
+				w.Header().Set("Grpc-Message", "unknown service")
+				w.WriteHeader(http.StatusOK)
+			},
+			code:    grpcUnimplemented,
+			message: "unknown service",
+		},
+		{
+			name: "missing status",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/grpc")
+				w.Write(grpcFrame(false, []byte("one")))
+			},
+			wantErr: "missing gRPC status",
+		},
+		{
+			name: "invalid status",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Grpc-Status", "ok")
+			},
+			wantErr: `invalid gRPC status "ok"`,
+		},
+		{
+			name: "HTTP error",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusBadGateway)
+			},
+			wantErr: "returned HTTP status 502",
+		},
+		{
+			name: "compressed message",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.Write(grpcFrame(true, []byte("zip")))
+				w.Header().Set(http.TrailerPrefix+"Grpc-Status", "0")
+			},
+			wantErr: "compressed gRPC messages are not supported",
+		},
+		{
+			name: "truncated message",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.Write(grpcFrame(false, []byte("complete"))[:7])
+			},
+			wantErr: "unexpected EOF",
+		},
+		{
+			name: "message too large",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.Write([]byte{0, 0xff, 0xff, 0xff, 0xff})
+			},
+			wantErr: "gRPC message too large",
+		},
+	}
+	for _, test := range tests {
+		conn := newGRPCTestServer(t, test.handler)
+		responses, err := conn.call(context.Background(), "/test.Service/Method", []byte("request"))
+		var statusErr *grpcStatusError
+		switch {
+		case test.code != 0:
+			if !errors.As(err, &statusErr) || statusErr.code != test.code || statusErr.message != test.message {
+				t.Errorf("%s: error %v, want code %d with message %q", test.name, err, test.code, test.message)
+			}
+		case test.wantErr != "":
+			if err == nil || !strings.Contains(err.Error(), test.wantErr) {
+				t.Errorf("%s: error %v, want %q", test.name, err, test.wantErr)
+			}
+		case err != nil:
+			t.Errorf("%s: unexpected error %v", test.name, err)
+		default:
+			if len(responses) != len(test.responses) {
+				t.Fatalf("%s: got %d responses, want %d", test.name, len(responses), len(test.responses))
+			}
+			for i, want := range test.responses {
+				if string(responses[i]) != want {
+					t.Errorf("%s: response %d is %q, want %q", test.name, i, responses[i], want)
+				}
+			}
+		}
+	}
+}
+
+func TestGRPCConnCallRequest(t *testing.T) {
+	requests := make(chan *http.Request, 1)
+	bodies := make(chan []byte, 1)
+	conn := newGRPCTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		requests <- r
+		bodies <- body
+		w.Header().Set("Grpc-Status", "0")
+	})
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if _, err := conn.call(ctx, "/test.Service/Method", []byte("a"), []byte("bc")); err != nil {
+		t.Fatal(err)
+	}
+	r, body := <-requests, <-bodies
+	if r.ProtoMajor != 2 || r.Method != http.MethodPost || r.URL.Path != "/test.Service/Method" {
+		t.Errorf("request %s %s over HTTP/%d", r.Method, r.URL.Path, r.ProtoMajor)
+	}
+	if r.Host != "service.example" {
+		t.Errorf("authority %q, want %q", r.Host, "service.example")
+	}
+	for header, want := range map[string]string{
+		"Content-Type":  "application/grpc",
+		"Te":            "trailers",
+		"Authorization": "Bearer token",
+	} {
+		if got := r.Header.Get(header); got != want {
+			t.Errorf("header %s is %q, want %q", header, got, want)
+		}
+	}
+	if timeout := r.Header.Get("Grpc-Timeout"); !strings.HasSuffix(timeout, "m") || timeout == "0m" {
+		t.Errorf("grpc-timeout %q, want milliseconds", timeout)
+	}
+	want := append(grpcFrame(false, []byte("a")), grpcFrame(false, []byte("bc"))...)
+	if string(body) != string(want) {
+		t.Errorf("body %q, want %q", body, want)
+	}
+}
@@ -3,11 +3,18 @@ package healthcheck
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
 	"fmt"
+	"io"
+	"math"
 	"net"
 	"net/http"
 	"runtime"
+	"runtime/metrics"
+	"strings"
 	"time"
+
+	"golang.org/x/net/http2"
 )
 
 // TCPDialCheck returns a Check that checks TCP connectivity to the provided
@@ -24,7 +31,7 @@ func TCPDialCheck(addr string, timeout time.Duration) func(ctx context.Context)
 
 // HTTPGetCheck returns a Check that performs an HTTP GET request against the
 // specified URL. The check fails if the response times out or returns a non-200
-// status code.
+// status code. See HTTPCheck for other status codes and content validation.
 func HTTPGetCheck(url string, timeout time.Duration) func(ctx context.Context) error {
 	client := http.Client{
 		Timeout: timeout,
@@ -46,6 +53,86 @@ func HTTPGetCheck(url string, timeout time.Duration) func(ctx context.Context) e
 	}
 }
 
+// HTTPProtocolCheck returns a Check that performs an HTTP GET request against
+// the specified URL and fails if the negotiated protocol major version is
+// lower than minProtoMajor, in addition to the conditions of HTTPGetCheck.
+// If transport is nil, HTTP/2 is forced for minProtoMajor 2: https URLs fail
+// if the server only negotiates HTTP/1.1 via ALPN, e.g. behind a
+// misconfigured proxy, and http URLs are requested with prior knowledge
+// (h2c), as gRPC clients do. As the standard library does not support
+// QUIC, HTTP/3 requires a transport such as the http3.RoundTripper of
+// github.com/quic-go/quic-go.
+func HTTPProtocolCheck(url string, minProtoMajor int, transport http.RoundTripper, timeout time.Duration) func(ctx context.Context) error {
+	if transport == nil && minProtoMajor == 2 {
+		if strings.HasPrefix(url, "http://") {
+			transport = h2cTransport()
+		} else {
+			transport = &http2.Transport{}
+		}
+	}
+	client := http.Client{
+		Transport: transport,
+		Timeout:   timeout,
+		// never follow redirects
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+	return func(ctx context.Context) error {
+		if transport == nil && minProtoMajor > 2 {
+			return fmt.Errorf("HTTP/%d requires a transport supporting it", minProtoMajor)
+		}
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+		if err != nil {
+			return err
+		}
+		resp, err := client.Do(req)
+		if err != nil {
+			return err
+		}
+		resp.Body.Close()
+		if resp.ProtoMajor < minProtoMajor {
+			return fmt.Errorf("negotiated %s, expected at least HTTP/%d", resp.Proto, minProtoMajor)
+		}
+		if resp.StatusCode != 200 {
+			return fmt.Errorf("returned status %d", resp.StatusCode)
+		}
+		return nil
+	}
+}
+
+// ThroughputCheck returns a Check that downloads the object at the specified
+// URL and fails if the download is slower than minBytesPerSecond or does not
+// complete within the timeout. This is meant for small objects and edge
+// deployments on unreliable links.
+func ThroughputCheck(url string, minBytesPerSecond float64, timeout time.Duration) func(ctx context.Context) error {
+	client := http.Client{Timeout: timeout}
+	return func(ctx context.Context) error {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+		if err != nil {
+			return err
+		}
+		start := time.Now()
+		resp, err := client.Do(req)
+		if err != nil {
+			return err
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode != 200 {
+			return fmt.Errorf("returned status %d", resp.StatusCode)
+		}
+		n, err := io.Copy(io.Discard, resp.Body)
+		if err != nil {
+			return err
+		}
+		throughput := float64(n) / time.Since(start).Seconds()
+		if throughput < minBytesPerSecond {
+			return fmt.Errorf("throughput %.0f B/s < %.0f B/s", throughput, minBytesPerSecond)
+		}
+		return nil
+	}
+}
+
 // DatabasePingCheck returns a Check that validates connectivity to a
 // database/sql.DB using Ping().
 func DatabasePingCheck(database *sql.DB, timeout time.Duration) func(ctx context.Context) error {
@@ -77,6 +164,100 @@ func DNSResolveCheck(host string, timeout time.Duration) func(ctx context.Contex
 	}
 }
 
+// rdapBootstrapURL is the RDAP service redirecting domain queries to the
+// authoritative RDAP server of the registry.
+const rdapBootstrapURL = "https://rdap.org/"
+
+// DomainExpiryCheck returns a Check that queries the RDAP server at rdapURL
+// (e.g., "https://rdap.example.net/") for the registration expiration date of
+// domain. If rdapURL is empty, the query is sent to https://rdap.org/, which
+// redirects it to the RDAP server of the registry. The check reports the
+// system as degraded (see Degraded) when the domain expires within warnBefore
+// and fails once it has expired.
+func DomainExpiryCheck(domain, rdapURL string, warnBefore time.Duration, timeout time.Duration) func(ctx context.Context) error {
+	if rdapURL == "" {
+		rdapURL = rdapBootstrapURL
+	}
+	endpoint := strings.TrimSuffix(rdapURL, "/") + "/domain/" + domain
+	client := http.Client{Timeout: timeout}
+	return func(ctx context.Context) error {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+		if err != nil {
+			return err
+		}
+		req.Header.Set("Accept", "application/rdap+json")
+		resp, err := client.Do(req)
+		if err != nil {
+			return err
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode != 200 {
+			return fmt.Errorf("returned status %d", resp.StatusCode)
+		}
+		var body struct {
+			Events []struct {
+				Action string    `json:"eventAction"`
+				Date   time.Time `json:"eventDate"`
+			} `json:"events"`
+		}
+		if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+			return err
+		}
+		for _, event := range body.Events {
+			if event.Action != "expiration" {
+				continue
+			}
+			remaining := time.Until(event.Date)
+			if remaining <= 0 {
+				return fmt.Errorf("domain %s expired on %s", domain, event.Date.Format("2006-01-02"))
+			}
+			if remaining < warnBefore {
+				return Degraded(fmt.Errorf("domain %s expires on %s", domain, event.Date.Format("2006-01-02")))
+			}
+			return nil
+		}
+		return fmt.Errorf("no expiration date published for domain %s", domain)
+	}
+}
+
+// VaultCheck returns a Check that queries the health endpoint of the Vault
+// server at addr (e.g., "https://vault:8200") and fails if Vault is
+// uninitialized or sealed, or if it is a standby node and standbyOK is not
+// set. Performance standbys count as standby.
+func VaultCheck(addr string, standbyOK bool, timeout time.Duration) func(ctx context.Context) error {
+	client := http.Client{Timeout: timeout}
+	return func(ctx context.Context) error {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, strings.TrimSuffix(addr, "/")+"/v1/sys/health", nil)
+		if err != nil {
+			return err
+		}
+		resp, err := client.Do(req)
+		if err != nil {
+			return err
+		}
+		defer resp.Body.Close()
+		// The status code encodes the state, but the body is more explicit.
+		var body struct {
+			Initialized        bool `json:"initialized"`
+			Sealed             bool `json:"sealed"`
+			Standby            bool `json:"standby"`
+			PerformanceStandby bool `json:"performance_standby"`
+		}
+		if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+			return fmt.Errorf("invalid response (status %d): %w", resp.StatusCode, err)
+		}
+		switch {
+		case !body.Initialized:
+			return fmt.Errorf("vault is not initialized")
+		case body.Sealed:
+			return fmt.Errorf("vault is sealed")
+		case (body.Standby || body.PerformanceStandby) && !standbyOK:
+			return fmt.Errorf("vault is in standby")
+		}
+		return nil
+	}
+}
+
 // GoroutineCountCheck returns a Check that fails if too many goroutines are
 // running (which could indicate a resource leak).
 func GoroutineCountCheck(threshold int) func(ctx context.Context) error {
@@ -104,3 +285,55 @@ func GCMaxPauseCheck(threshold time.Duration) func(ctx context.Context) error {
 		return nil
 	}
 }
+
+// HeapAllocCheck returns a Check that fails if the Go heap holds more than
+// maxBytes of allocated objects, including unreachable objects not yet freed
+// by the garbage collector. Unlike runtime.ReadMemStats, reading the value
+// does not stop the world.
+func HeapAllocCheck(maxBytes uint64) func(ctx context.Context) error {
+	return func(ctx context.Context) error {
+		samples := []metrics.Sample{{Name: "/memory/classes/heap/objects:bytes"}}
+		metrics.Read(samples)
+		if samples[0].Value.Kind() != metrics.KindUint64 {
+			return fmt.Errorf("heap allocation not available")
+		}
+		if alloc := samples[0].Value.Uint64(); alloc > maxBytes {
+			return fmt.Errorf("heap allocation %d bytes > %d bytes", alloc, maxBytes)
+		}
+		return nil
+	}
+}
+
+// MemoryPressureCheck returns a Check that compares the memory used by the Go
+// runtime against the soft memory limit (GOMEMLIMIT). The check reports the
+// system as degraded when usage exceeds the soft ratio of the limit and fails
+// when it exceeds the hard ratio. The check always passes when no memory
+// limit is set or the runtime does not support memory limits.
+func MemoryPressureCheck(softRatio, hardRatio float64) func(ctx context.Context) error {
+	return func(ctx context.Context) error {
+		samples := []metrics.Sample{
+			{Name: "/gc/gomemlimit:bytes"},
+			{Name: "/memory/classes/total:bytes"},
+			{Name: "/memory/classes/heap/released:bytes"},
+		}
+		metrics.Read(samples)
+		for _, sample := range samples {
+			if sample.Value.Kind() != metrics.KindUint64 {
+				return nil
+			}
+		}
+		limit := samples[0].Value.Uint64()
+		if limit == math.MaxInt64 {
+			return nil
+		}
+		used := samples[1].Value.Uint64() - samples[2].Value.Uint64()
+		ratio := float64(used) / float64(limit)
+		if ratio > hardRatio {
+			return fmt.Errorf("memory usage %d bytes is above %.0f%% of the memory limit %d bytes", used, hardRatio*100, limit)
+		}
+		if ratio > softRatio {
+			return Degraded(fmt.Errorf("memory usage %d bytes is above %.0f%% of the memory limit %d bytes", used, softRatio*100, limit))
+		}
+		return nil
+	}
+}
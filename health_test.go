@@ -0,0 +1,95 @@
+package healthcheck
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"golang.org/x/net/http2"
+	"golang.org/x/net/http2/h2c"
+)
+
+func TestHTTPProtocolCheckH2C(t *testing.T) {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+	h2cServer := httptest.NewServer(h2c.NewHandler(handler, &http2.Server{}))
+	defer h2cServer.Close()
+	http1Server := httptest.NewServer(handler)
+	defer http1Server.Close()
+
+	if err := HTTPProtocolCheck(h2cServer.URL, 2, nil, time.Second)(context.Background()); err != nil {
+		t.Errorf("h2c server: %v", err)
+	}
+	if err := HTTPProtocolCheck(http1Server.URL, 2, nil, time.Second)(context.Background()); err == nil {
+		t.Error("HTTP/1.1 server: no error")
+	}
+	if err := HTTPProtocolCheck(http1Server.URL, 1, nil, time.Second)(context.Background()); err != nil {
+		t.Errorf("HTTP/1.1 server with minimum HTTP/1: %v", err)
+	}
+}
+
+func TestHTTPProtocolCheckTLS(t *testing.T) {
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	server.EnableHTTP2 = true
+	server.StartTLS()
+	defer server.Close()
+	transport := &http2.Transport{TLSClientConfig: server.Client().Transport.(*http.Transport).TLSClientConfig}
+	if !strings.HasPrefix(server.URL, "https://") {
+		t.Fatalf("server URL %s", server.URL)
+	}
+	if err := HTTPProtocolCheck(server.URL, 2, transport, time.Second)(context.Background()); err != nil {
+		t.Errorf("HTTP/2 over TLS: %v", err)
+	}
+}
+
+func TestDomainExpiryCheck(t *testing.T) {
+	expiration := map[string]time.Time{
+		"valid.example":    time.Now().AddDate(1, 0, 0),
+		"expiring.example": time.Now().AddDate(0, 0, 10),
+		"expired.example":  time.Now().AddDate(0, 0, -1),
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		date, ok := expiration[strings.TrimPrefix(r.URL.Path, "/domain/")]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", "application/rdap+json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"events": []map[string]interface{}{
+				{"eventAction": "registration", "eventDate": date.AddDate(-5, 0, 0)},
+				{"eventAction": "expiration", "eventDate": date},
+			},
+		})
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		domain   string
+		wantErr  string
+		degraded bool
+	}{
+		{"valid.example", "", false},
+		{"expiring.example", "domain expiring.example expires on", true},
+		{"expired.example", "domain expired.example expired on", false},
+		{"unknown.example", "returned status 404", false},
+	}
+	for _, test := range tests {
+		err := DomainExpiryCheck(test.domain, server.URL+"/", 30*24*time.Hour, time.Second)(context.Background())
+		if test.wantErr == "" {
+			if err != nil {
+				t.Errorf("%s: %v", test.domain, err)
+			}
+			continue
+		}
+		if err == nil || !strings.Contains(err.Error(), test.wantErr) {
+			t.Errorf("%s: error %v, want %q", test.domain, err, test.wantErr)
+		}
+		if IsDegraded(err) != test.degraded {
+			t.Errorf("%s: degraded %v, want %v", test.domain, IsDegraded(err), test.degraded)
+		}
+	}
+}
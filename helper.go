@@ -0,0 +1,56 @@
+package healthcheck
+
+import "time"
+
+// CheckOption customizes a check registered by one of the AddXxxCheck
+// helpers of AndictlCheckerConfig.
+type CheckOption func(check *helperCheck)
+
+// helperCheck holds the settings of a check registered by a helper.
+type helperCheck struct {
+	name string
+	// timeout is the check specific timeout (see Check.Timeout).
+	timeout time.Duration
+	// pingTimeout bounds the request sent to the component, e.g. a ping. It
+	// is shorter than timeout, so a slow component fails with its own error
+	// rather than a timeout.
+	pingTimeout time.Duration
+}
+
+// CheckName replaces the default name of the check, e.g. to register
+// several checks of the same kind.
+func CheckName(name string) CheckOption {
+	return func(check *helperCheck) {
+		check.name = name
+	}
+}
+
+// CheckTimeout replaces the default check specific timeout, which is one
+// second more than the ping timeout.
+func CheckTimeout(timeout time.Duration) CheckOption {
+	return func(check *helperCheck) {
+		check.timeout = timeout
+	}
+}
+
+// PingTimeout replaces the default timeout of the request sent to the
+// component. Helpers taking an options struct default it to
+// options.Timeout.
+func PingTimeout(timeout time.Duration) CheckOption {
+	return func(check *helperCheck) {
+		check.pingTimeout = timeout
+	}
+}
+
+// newHelperCheck returns the settings of a helper check with the given
+// defaults, customized by options.
+func newHelperCheck(name string, pingTimeout time.Duration, options []CheckOption) helperCheck {
+	check := helperCheck{name: name, pingTimeout: pingTimeout}
+	for _, option := range options {
+		option(&check)
+	}
+	if check.timeout == 0 {
+		check.timeout = check.pingTimeout + 1*time.Second
+	}
+	return check
+}
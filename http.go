@@ -0,0 +1,127 @@
+package healthcheck
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// HTTPCheckOptions configures HTTPCheck.
+type HTTPCheckOptions struct {
+	// Method is the request method (e.g., HEAD or POST). Default is GET.
+	Method string
+	// Headers are added to the request.
+	Headers map[string]string
+	// Body is sent as request body.
+	Body string
+	// Username and Password are sent as basic authentication if Username is
+	// not empty.
+	Username string
+	Password string
+	// BearerToken is sent as bearer token in the Authorization header if not
+	// empty.
+	BearerToken string
+	// ExpectedStatus holds the acceptable status codes. Default is 200.
+	ExpectedStatus []int
+	// BodyContains is a substring the response body must contain.
+	BodyContains string
+	// BodyRegexp is a regular expression the response body must match.
+	BodyRegexp string
+	// JSONPath is a dot separated path into the JSON body (e.g.,
+	// "status" or "data.items.0.id") that must exist.
+	JSONPath string
+	// JSONValue is the expected value at JSONPath. Values other than strings
+	// are compared JSON encoded (e.g., "true" or "3").
+	JSONValue string
+	// Timeout for the whole check.
+	Timeout time.Duration
+}
+
+// HTTPCheck returns a Check that performs an HTTP request as configured by
+// options against the specified URL and fails unless the response meets the
+// expectations of options. Redirects are not followed, so they can be
+// expected as status.
+func HTTPCheck(url string, options HTTPCheckOptions) func(ctx context.Context) error {
+	client := http.Client{
+		Timeout: options.Timeout,
+		// never follow redirects
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+	method := options.Method
+	if method == "" {
+		method = http.MethodGet
+	}
+	expectedStatus := map[int]bool{}
+	for _, code := range options.ExpectedStatus {
+		expectedStatus[code] = true
+	}
+	if len(expectedStatus) == 0 {
+		expectedStatus[http.StatusOK] = true
+	}
+	var bodyRegexp *regexp.Regexp
+	var compileErr error
+	if options.BodyRegexp != "" {
+		bodyRegexp, compileErr = regexp.Compile(options.BodyRegexp)
+	}
+	readBody := options.BodyContains != "" || bodyRegexp != nil || options.JSONPath != ""
+	return func(ctx context.Context) error {
+		if compileErr != nil {
+			return compileErr
+		}
+		var body io.Reader
+		if options.Body != "" {
+			body = strings.NewReader(options.Body)
+		}
+		req, err := http.NewRequestWithContext(ctx, method, url, body)
+		if err != nil {
+			return err
+		}
+		for key, value := range options.Headers {
+			req.Header.Set(key, value)
+		}
+		if options.Username != "" {
+			req.SetBasicAuth(options.Username, options.Password)
+		}
+		if options.BearerToken != "" {
+			req.Header.Set("Authorization", "Bearer "+options.BearerToken)
+		}
+		resp, err := client.Do(req)
+		if err != nil {
+			return err
+		}
+		defer resp.Body.Close()
+		if !expectedStatus[resp.StatusCode] {
+			return fmt.Errorf("returned status %d", resp.StatusCode)
+		}
+		if !readBody {
+			return nil
+		}
+		content, err := io.ReadAll(io.LimitReader(resp.Body, 10<<20))
+		if err != nil {
+			return err
+		}
+		if options.BodyContains != "" && !bytes.Contains(content, []byte(options.BodyContains)) {
+			return fmt.Errorf("response body does not contain %q", options.BodyContains)
+		}
+		if bodyRegexp != nil && !bodyRegexp.Match(content) {
+			return fmt.Errorf("response body does not match %s", options.BodyRegexp)
+		}
+		if options.JSONPath != "" {
+			value, err := lookupJSONString(content, options.JSONPath)
+			if err != nil {
+				return err
+			}
+			if options.JSONValue != "" && value != options.JSONValue {
+				return fmt.Errorf("%s is %s, expected %s", options.JSONPath, value, options.JSONValue)
+			}
+		}
+		return nil
+	}
+}
@@ -0,0 +1,70 @@
+package healthcheck
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+// recoveryTracker holds back the recovery of checks with a success
+// threshold (see Check.SuccessThreshold) until they succeeded often enough in
+// a row.
+type recoveryTracker struct {
+	mtx        sync.Mutex
+	thresholds map[string]int
+	// recovering maps checks that were reported as down to the number of
+	// consecutive successful executions since.
+	recovering map[string]int
+}
+
+// newRecoveryTracker returns the tracker of the checks with a success
+// threshold, or nil if there are none.
+func newRecoveryTracker(checks ...[]scheduledCheck) *recoveryTracker {
+	thresholds := map[string]int{}
+	for _, list := range checks {
+		for _, check := range list {
+			if check.SuccessThreshold > 1 {
+				thresholds[check.Name] = check.SuccessThreshold
+			}
+		}
+	}
+	if len(thresholds) == 0 {
+		return nil
+	}
+	return &recoveryTracker{thresholds: thresholds, recovering: map[string]int{}}
+}
+
+// intercept is an interceptor reporting recovering checks as down.
+func (rt *recoveryTracker) intercept(next interceptorFunc) interceptorFunc {
+	return func(ctx context.Context, name string, state checkState) checkState {
+		state = next(ctx, name, state)
+		threshold, ok := rt.thresholds[name]
+		if !ok {
+			return state
+		}
+		rt.mtx.Lock()
+		defer rt.mtx.Unlock()
+		if state.Status == StatusDown {
+			rt.recovering[name] = 0
+			return state
+		}
+		successes, ok := rt.recovering[name]
+		if !ok {
+			return state
+		}
+		// A failure below the failure threshold restarts the recovery.
+		if state.Result != nil && !IsDegraded(state.Result) {
+			rt.recovering[name] = 0
+			state.Status = StatusDown
+			return state
+		}
+		if successes++; successes >= threshold {
+			delete(rt.recovering, name)
+			return state
+		}
+		rt.recovering[name] = successes
+		state.Status = StatusDown
+		state.Result = fmt.Errorf("recovering: %d of %d consecutive successful executions", successes, threshold)
+		return state
+	}
+}
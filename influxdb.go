@@ -0,0 +1,106 @@
+package healthcheck
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// influxDBHealth is the response of the /health endpoint.
+type influxDBHealth struct {
+	Name    string           `json:"name"`
+	Message string           `json:"message"`
+	Status  string           `json:"status"`
+	Checks  []influxDBHealth `json:"checks"`
+}
+
+// InfluxDBCheck returns a Check that queries the /health endpoint of the
+// InfluxDB server at baseURL (e.g., "http://localhost:8086") and fails
+// unless it reports the status "pass"; "warn" is reported as degraded (see
+// Degraded). Servers without /health, such as InfluxDB 1.7 and older, are
+// checked with /ping instead. token is sent as API token if not empty.
+func InfluxDBCheck(baseURL, token string, timeout time.Duration) func(ctx context.Context) error {
+	client := http.Client{Timeout: timeout}
+	base := strings.TrimSuffix(baseURL, "/")
+	get := func(ctx context.Context, path string) (*http.Response, error) {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, base+path, nil)
+		if err != nil {
+			return nil, err
+		}
+		if token != "" {
+			req.Header.Set("Authorization", "Token "+token)
+		}
+		return client.Do(req)
+	}
+	return func(ctx context.Context) error {
+		ctx, cancel := withOptionalTimeout(ctx, timeout)
+		defer cancel()
+		resp, err := get(ctx, "/health")
+		if err != nil {
+			return err
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode == http.StatusNotFound {
+			ping, err := get(ctx, "/ping")
+			if err != nil {
+				return err
+			}
+			defer ping.Body.Close()
+			if ping.StatusCode != http.StatusNoContent && ping.StatusCode != http.StatusOK {
+				return fmt.Errorf("ping returned status %d", ping.StatusCode)
+			}
+			return nil
+		}
+		// A failing server answers with status 503 but still reports its
+		// health.
+		if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusServiceUnavailable {
+			return fmt.Errorf("returned status %d", resp.StatusCode)
+		}
+		body, err := io.ReadAll(io.LimitReader(resp.Body, 64<<10))
+		if err != nil {
+			return err
+		}
+		var health influxDBHealth
+		if err := json.Unmarshal(body, &health); err != nil {
+			// InfluxDB 3 answers with a plain "OK".
+			if resp.StatusCode == http.StatusOK && strings.TrimSpace(string(body)) == "OK" {
+				return nil
+			}
+			return fmt.Errorf("invalid health response: %w", err)
+		}
+		switch health.Status {
+		case "pass":
+			return nil
+		case "warn":
+			return Degraded(influxDBHealthError(health))
+		case "fail":
+			return influxDBHealthError(health)
+		default:
+			return fmt.Errorf("unknown health status %q", health.Status)
+		}
+	}
+}
+
+// influxDBHealthError describes an unhealthy status, including the names and
+// messages of the failed sub-checks.
+func influxDBHealthError(health influxDBHealth) error {
+	message := fmt.Sprintf("%s is %s", health.Name, health.Status)
+	if health.Message != "" {
+		message += ": " + health.Message
+	}
+	var failed []string
+	for _, check := range health.Checks {
+		if check.Status != "pass" {
+			failed = append(failed, fmt.Sprintf("%s: %s", check.Name, check.Message))
+		}
+	}
+	if len(failed) > 0 {
+		message += " (" + strings.Join(failed, "; ") + ")"
+	}
+	return errors.New(message)
+}
@@ -0,0 +1,212 @@
+package healthcheck
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// KafkaBrokerCheck returns a Check that fetches the cluster metadata from
+// the first reachable bootstrap broker and fails if fewer than minBrokers of
+// the brokers in the cluster answer a request. Unlike a TCPDialCheck, this
+// notices brokers whose port is open but that do not serve requests. TLS is
+// used if config is not nil.
+func KafkaBrokerCheck(bootstrap []string, minBrokers int, config *tls.Config, timeout time.Duration) func(ctx context.Context) error {
+	return func(ctx context.Context) error {
+		ctx, cancel := withOptionalTimeout(ctx, timeout)
+		defer cancel()
+		brokers, err := kafkaClusterBrokers(ctx, bootstrap, config)
+		if err != nil {
+			return err
+		}
+		var mtx sync.Mutex
+		var wg sync.WaitGroup
+		var failures []string
+		for _, broker := range brokers {
+			wg.Add(1)
+			go func(broker string) {
+				defer wg.Done()
+				if err := kafkaPing(ctx, broker, config); err != nil {
+					mtx.Lock()
+					failures = append(failures, fmt.Sprintf("%s: %v", broker, err))
+					mtx.Unlock()
+				}
+			}(broker)
+		}
+		wg.Wait()
+		if reachable := len(brokers) - len(failures); reachable < minBrokers {
+			return fmt.Errorf("%d of %d brokers reachable, expected at least %d: %s",
+				reachable, len(brokers), minBrokers, strings.Join(failures, "; "))
+		}
+		return nil
+	}
+}
+
+// kafkaClusterBrokers returns the addresses of all brokers of the cluster,
+// as reported by the first bootstrap broker that answers.
+func kafkaClusterBrokers(ctx context.Context, bootstrap []string, config *tls.Config) ([]string, error) {
+	var errs []string
+	for _, addr := range bootstrap {
+		conn, err := dialKafka(ctx, addr, config)
+		if err != nil {
+			errs = append(errs, err.Error())
+			continue
+		}
+		// Metadata v1 with an empty topic list returns the brokers only.
+		resp, err := conn.request(3, 1, []byte{0, 0, 0, 0})
+		conn.Close()
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("%s: %v", addr, err))
+			continue
+		}
+		brokers, err := parseKafkaBrokers(resp)
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("%s: %v", addr, err))
+			continue
+		}
+		return brokers, nil
+	}
+	return nil, fmt.Errorf("no bootstrap broker reachable: %s", strings.Join(errs, "; "))
+}
+
+// parseKafkaBrokers parses the broker list of a Metadata v1 response.
+func parseKafkaBrokers(resp []byte) ([]string, error) {
+	r := kafkaReader{data: resp}
+	n := r.int32()
+	var brokers []string
+	for i := int32(0); i < n && r.err == nil; i++ {
+		r.int32() // node id
+		host := r.string()
+		port := r.int32()
+		r.string() // rack
+		brokers = append(brokers, net.JoinHostPort(host, strconv.Itoa(int(port))))
+	}
+	if r.err != nil {
+		return nil, r.err
+	}
+	if len(brokers) == 0 {
+		return nil, errors.New("metadata lists no brokers")
+	}
+	return brokers, nil
+}
+
+// kafkaPing sends an ApiVersions request to the broker.
+func kafkaPing(ctx context.Context, addr string, config *tls.Config) error {
+	conn, err := dialKafka(ctx, addr, config)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	resp, err := conn.request(18, 0, nil)
+	if err != nil {
+		return err
+	}
+	r := kafkaReader{data: resp}
+	if code := r.int16(); r.err == nil && code != 0 {
+		return fmt.Errorf("ApiVersions failed with error code %d", code)
+	}
+	return r.err
+}
+
+// kafkaConn is a minimal client of the Kafka protocol.
+type kafkaConn struct {
+	net.Conn
+	correlationID int32
+}
+
+func dialKafka(ctx context.Context, addr string, config *tls.Config) (*kafkaConn, error) {
+	if config != nil && config.ServerName == "" {
+		host, _, _ := net.SplitHostPort(addr)
+		config = config.Clone()
+		config.ServerName = host
+	}
+	conn, err := dialTCP(ctx, addr, config)
+	if err != nil {
+		return nil, err
+	}
+	return &kafkaConn{Conn: conn}, nil
+}
+
+const kafkaClientID = "healthcheck"
+
+// request sends a request with header v1 and returns the response body.
+func (kc *kafkaConn) request(apiKey, apiVersion int16, body []byte) ([]byte, error) {
+	kc.correlationID++
+	msg := make([]byte, 4, 14+len(kafkaClientID)+len(body))
+	msg = append(msg, byte(apiKey>>8), byte(apiKey), byte(apiVersion>>8), byte(apiVersion))
+	msg = appendUint32(msg, uint32(kc.correlationID))
+	msg = append(msg, byte(len(kafkaClientID)>>8), byte(len(kafkaClientID)))
+	msg = append(msg, kafkaClientID...)
+	msg = append(msg, body...)
+	binary.BigEndian.PutUint32(msg, uint32(len(msg)-4))
+	if _, err := kc.Write(msg); err != nil {
+		return nil, err
+	}
+	header := make([]byte, 8)
+	if _, err := io.ReadFull(kc, header); err != nil {
+		return nil, err
+	}
+	size := binary.BigEndian.Uint32(header)
+	if size < 4 || size > 16<<20 {
+		return nil, fmt.Errorf("invalid response size %d", size)
+	}
+	if id := int32(binary.BigEndian.Uint32(header[4:])); id != kc.correlationID {
+		return nil, fmt.Errorf("unexpected correlation id %d", id)
+	}
+	resp := make([]byte, size-4)
+	if _, err := io.ReadFull(kc, resp); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// kafkaReader decodes the primitive types of the Kafka protocol. The first
+// error is kept in err; subsequent reads return zero values.
+type kafkaReader struct {
+	data []byte
+	err  error
+}
+
+func (r *kafkaReader) next(n int) []byte {
+	if r.err != nil {
+		return nil
+	}
+	if n < 0 || n > len(r.data) {
+		r.err = errors.New("truncated response")
+		return nil
+	}
+	b := r.data[:n]
+	r.data = r.data[n:]
+	return b
+}
+
+func (r *kafkaReader) int16() int16 {
+	if b := r.next(2); b != nil {
+		return int16(binary.BigEndian.Uint16(b))
+	}
+	return 0
+}
+
+func (r *kafkaReader) int32() int32 {
+	if b := r.next(4); b != nil {
+		return int32(binary.BigEndian.Uint32(b))
+	}
+	return 0
+}
+
+// string reads a nullable string; null is returned as "".
+func (r *kafkaReader) string() string {
+	n := r.int16()
+	if n < 0 {
+		return ""
+	}
+	return string(r.next(int(n)))
+}
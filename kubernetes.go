@@ -0,0 +1,237 @@
+package healthcheck
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// serviceAccountDir holds the credentials mounted into pods.
+const serviceAccountDir = "/var/run/secrets/kubernetes.io/serviceaccount"
+
+// KubernetesAPICheck returns a Check that queries the /readyz endpoint of the
+// Kubernetes API server and fails unless the server reports ready. The
+// server and credentials are read from the current context of the
+// kubeconfig file, or from the service account of the pod if kubeconfig is
+// empty. Credential plugins (exec and auth-provider) are not supported.
+func KubernetesAPICheck(kubeconfig string, timeout time.Duration) func(ctx context.Context) error {
+	return func(ctx context.Context) error {
+		ctx, cancel := withOptionalTimeout(ctx, timeout)
+		defer cancel()
+		// Credentials are loaded on every execution as service account
+		// tokens are rotated.
+		target, err := loadKubernetesTarget(kubeconfig)
+		if err != nil {
+			return err
+		}
+		client := &http.Client{Transport: &http.Transport{
+			TLSClientConfig:   target.tls,
+			DisableKeepAlives: true,
+		}}
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, strings.TrimSuffix(target.server, "/")+"/readyz?verbose", nil)
+		if err != nil {
+			return err
+		}
+		if target.token != "" {
+			req.Header.Set("Authorization", "Bearer "+target.token)
+		} else if target.username != "" {
+			req.SetBasicAuth(target.username, target.password)
+		}
+		resp, err := client.Do(req)
+		if err != nil {
+			return err
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode == http.StatusOK {
+			return nil
+		}
+		body, err := io.ReadAll(io.LimitReader(resp.Body, 64<<10))
+		if err != nil {
+			return err
+		}
+		// The verbose output lists failed checks as "[-]name failed: reason".
+		var failed []string
+		scanner := bufio.NewScanner(bytes.NewReader(body))
+		for scanner.Scan() {
+			if line := scanner.Text(); strings.HasPrefix(line, "[-]") {
+				failed = append(failed, strings.TrimPrefix(line, "[-]"))
+			}
+		}
+		if len(failed) > 0 {
+			return fmt.Errorf("API server is not ready: %s", strings.Join(failed, "; "))
+		}
+		return fmt.Errorf("returned status %d", resp.StatusCode)
+	}
+}
+
+// kubernetesTarget is an API server and the credentials to access it.
+type kubernetesTarget struct {
+	server             string
+	tls                *tls.Config
+	token              string
+	username, password string
+}
+
+// kubeConfig is the subset of the kubeconfig file format used to access
+// the API server.
+type kubeConfig struct {
+	CurrentContext string `yaml:"current-context"`
+	Contexts       []struct {
+		Name    string `yaml:"name"`
+		Context struct {
+			Cluster string `yaml:"cluster"`
+			User    string `yaml:"user"`
+		} `yaml:"context"`
+	} `yaml:"contexts"`
+	Clusters []struct {
+		Name    string `yaml:"name"`
+		Cluster struct {
+			Server                   string `yaml:"server"`
+			TLSServerName            string `yaml:"tls-server-name"`
+			InsecureSkipTLSVerify    bool   `yaml:"insecure-skip-tls-verify"`
+			CertificateAuthority     string `yaml:"certificate-authority"`
+			CertificateAuthorityData string `yaml:"certificate-authority-data"`
+		} `yaml:"cluster"`
+	} `yaml:"clusters"`
+	Users []struct {
+		Name string `yaml:"name"`
+		User struct {
+			ClientCertificate     string `yaml:"client-certificate"`
+			ClientCertificateData string `yaml:"client-certificate-data"`
+			ClientKey             string `yaml:"client-key"`
+			ClientKeyData         string `yaml:"client-key-data"`
+			Token                 string `yaml:"token"`
+			TokenFile             string `yaml:"tokenFile"`
+			Username              string `yaml:"username"`
+			Password              string `yaml:"password"`
+		} `yaml:"user"`
+	} `yaml:"users"`
+}
+
+func loadKubernetesTarget(kubeconfig string) (*kubernetesTarget, error) {
+	if kubeconfig == "" {
+		return inClusterKubernetesTarget()
+	}
+	content, err := os.ReadFile(kubeconfig)
+	if err != nil {
+		return nil, err
+	}
+	var config kubeConfig
+	if err := yaml.Unmarshal(content, &config); err != nil {
+		return nil, fmt.Errorf("invalid kubeconfig: %w", err)
+	}
+	// Relative paths are relative to the kubeconfig file.
+	dir := filepath.Dir(kubeconfig)
+	read := func(data, path string) ([]byte, error) {
+		if data != "" {
+			return base64.StdEncoding.DecodeString(data)
+		}
+		if path == "" {
+			return nil, nil
+		}
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(dir, path)
+		}
+		return os.ReadFile(path)
+	}
+	var clusterName, userName string
+	found := false
+	for _, c := range config.Contexts {
+		if c.Name == config.CurrentContext {
+			clusterName, userName, found = c.Context.Cluster, c.Context.User, true
+		}
+	}
+	if !found {
+		return nil, fmt.Errorf("context %q not found in kubeconfig", config.CurrentContext)
+	}
+	target := &kubernetesTarget{tls: &tls.Config{}}
+	for _, cluster := range config.Clusters {
+		if cluster.Name != clusterName {
+			continue
+		}
+		target.server = cluster.Cluster.Server
+		target.tls.ServerName = cluster.Cluster.TLSServerName
+		target.tls.InsecureSkipVerify = cluster.Cluster.InsecureSkipTLSVerify
+		ca, err := read(cluster.Cluster.CertificateAuthorityData, cluster.Cluster.CertificateAuthority)
+		if err != nil {
+			return nil, err
+		}
+		if ca != nil {
+			target.tls.RootCAs = x509.NewCertPool()
+			if !target.tls.RootCAs.AppendCertsFromPEM(ca) {
+				return nil, fmt.Errorf("invalid certificate authority of cluster %s", clusterName)
+			}
+		}
+	}
+	if target.server == "" {
+		return nil, fmt.Errorf("cluster %q not found in kubeconfig", clusterName)
+	}
+	for _, user := range config.Users {
+		if user.Name != userName {
+			continue
+		}
+		target.username, target.password = user.User.Username, user.User.Password
+		target.token = user.User.Token
+		if target.token == "" && user.User.TokenFile != "" {
+			token, err := read("", user.User.TokenFile)
+			if err != nil {
+				return nil, err
+			}
+			target.token = strings.TrimSpace(string(token))
+		}
+		cert, err := read(user.User.ClientCertificateData, user.User.ClientCertificate)
+		if err != nil {
+			return nil, err
+		}
+		key, err := read(user.User.ClientKeyData, user.User.ClientKey)
+		if err != nil {
+			return nil, err
+		}
+		if cert != nil {
+			pair, err := tls.X509KeyPair(cert, key)
+			if err != nil {
+				return nil, err
+			}
+			target.tls.Certificates = []tls.Certificate{pair}
+		}
+	}
+	return target, nil
+}
+
+func inClusterKubernetesTarget() (*kubernetesTarget, error) {
+	host, port := os.Getenv("KUBERNETES_SERVICE_HOST"), os.Getenv("KUBERNETES_SERVICE_PORT")
+	if host == "" || port == "" {
+		return nil, errors.New("not running in a Kubernetes cluster")
+	}
+	token, err := os.ReadFile(filepath.Join(serviceAccountDir, "token"))
+	if err != nil {
+		return nil, err
+	}
+	ca, err := os.ReadFile(filepath.Join(serviceAccountDir, "ca.crt"))
+	if err != nil {
+		return nil, err
+	}
+	roots := x509.NewCertPool()
+	if !roots.AppendCertsFromPEM(ca) {
+		return nil, errors.New("invalid service account certificate authority")
+	}
+	return &kubernetesTarget{
+		server: "https://" + net.JoinHostPort(host, port),
+		tls:    &tls.Config{RootCAs: roots},
+		token:  strings.TrimSpace(string(token)),
+	}, nil
+}
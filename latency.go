@@ -0,0 +1,95 @@
+package healthcheck
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// LatencyAnomalyOptions configures the latency anomaly detector (see
+// EnableLatencyAnomalyDetection).
+type LatencyAnomalyOptions struct {
+	// Factor by which the latency of a check must exceed its baseline for the
+	// check to be reported as degraded. Default is 3.
+	Factor float64
+	// Smoothing is the weight of a new sample in the exponentially weighted
+	// moving average that makes up the baseline, between 0 and 1.
+	// Default is 0.1.
+	Smoothing float64
+	// WarmupSamples is the number of samples used to learn the baseline
+	// before anomalies are reported. Default is 10.
+	WarmupSamples int
+	// MinLatency is a floor below which latencies are never reported as
+	// anomalous, to ignore jitter on very fast checks. Default is 0.
+	MinLatency time.Duration
+}
+
+// EnableLatencyAnomalyDetection reports successful checks as degraded (see
+// StatusDegraded) when their latency deviates from the learned baseline by
+// more than the configured factor. This catches brownouts of dependencies
+// that never trip the check timeouts.
+func (c *AndictlCheckerConfig) EnableLatencyAnomalyDetection(options LatencyAnomalyOptions) {
+	if options.Factor <= 0 {
+		options.Factor = 3
+	}
+	if options.Smoothing <= 0 || options.Smoothing > 1 {
+		options.Smoothing = 0.1
+	}
+	if options.WarmupSamples <= 0 {
+		options.WarmupSamples = 10
+	}
+	c.latency = &options
+}
+
+type latencyBaseline struct {
+	average float64
+	samples int
+}
+
+type latencyAnomalyDetector struct {
+	mtx       sync.Mutex
+	options   LatencyAnomalyOptions
+	baselines map[string]*latencyBaseline
+}
+
+func newLatencyAnomalyDetector(options LatencyAnomalyOptions) *latencyAnomalyDetector {
+	return &latencyAnomalyDetector{options: options, baselines: map[string]*latencyBaseline{}}
+}
+
+// intercept is an interceptor measuring the latency of every check.
+func (d *latencyAnomalyDetector) intercept(next interceptorFunc) interceptorFunc {
+	return func(ctx context.Context, name string, state checkState) checkState {
+		start := time.Now()
+		state = next(ctx, name, state)
+		if state.Result != nil {
+			return state
+		}
+		if err := d.observe(name, time.Since(start)); err != nil {
+			state.Result = err
+			state.Status = StatusDegraded
+		}
+		return state
+	}
+}
+
+// observe adds a latency sample to the baseline of the named check and
+// returns an error if the sample is anomalous.
+func (d *latencyAnomalyDetector) observe(name string, latency time.Duration) error {
+	d.mtx.Lock()
+	defer d.mtx.Unlock()
+	baseline, ok := d.baselines[name]
+	if !ok {
+		baseline = &latencyBaseline{average: float64(latency)}
+		d.baselines[name] = baseline
+	}
+	var err error
+	if baseline.samples >= d.options.WarmupSamples && latency > d.options.MinLatency &&
+		float64(latency) > baseline.average*d.options.Factor {
+		err = fmt.Errorf("latency %s exceeds baseline %s by more than %.1fx",
+			latency, time.Duration(baseline.average), d.options.Factor)
+	}
+	baseline.average += d.options.Smoothing * (float64(latency) - baseline.average)
+	baseline.samples++
+	return err
+}
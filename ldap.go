@@ -0,0 +1,219 @@
+package healthcheck
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"time"
+)
+
+// LDAPCheck returns a Check that connects to the LDAP server at rawURL
+// (e.g., "ldap://ldap:389" or "ldaps://ldap:636") and performs a simple
+// bind with bindDN and password, or an anonymous bind if both are empty.
+// ldaps URLs use TLS with config, or the default configuration if config
+// is nil; ldap URLs with a config are upgraded with StartTLS. The check
+// fails on connection, TLS or authentication errors.
+func LDAPCheck(rawURL, bindDN, password string, config *tls.Config, timeout time.Duration) func(ctx context.Context) error {
+	return func(ctx context.Context) error {
+		ctx, cancel := withOptionalTimeout(ctx, timeout)
+		defer cancel()
+		u, err := url.Parse(rawURL)
+		if err != nil {
+			return err
+		}
+		port, startTLS := "389", config != nil
+		switch u.Scheme {
+		case "ldap":
+		case "ldaps":
+			port, startTLS = "636", false
+			if config == nil {
+				config = &tls.Config{}
+			}
+		default:
+			return fmt.Errorf("unsupported scheme %q", u.Scheme)
+		}
+		if config != nil && config.ServerName == "" {
+			config = config.Clone()
+			config.ServerName = u.Hostname()
+		}
+		addr := u.Host
+		if u.Port() == "" {
+			addr = net.JoinHostPort(u.Hostname(), port)
+		}
+		dialConfig := config
+		if startTLS {
+			dialConfig = nil
+		}
+		conn, err := dialTCP(ctx, addr, dialConfig)
+		if err != nil {
+			return err
+		}
+		defer func() { conn.Close() }()
+		lc := &ldapConn{Conn: conn, reader: bufio.NewReader(conn)}
+		if startTLS {
+			// ExtendedRequest with the StartTLS OID.
+			request := appendBER(nil, 0x80, []byte("1.3.6.1.4.1.1466.20037"))
+			if err := lc.request(0x77, request, 0x78); err != nil {
+				return fmt.Errorf("StartTLS: %w", err)
+			}
+			tlsConn := tls.Client(conn, config)
+			if err := tlsConn.HandshakeContext(ctx); err != nil {
+				return err
+			}
+			conn = tlsConn
+			lc = &ldapConn{Conn: tlsConn, reader: bufio.NewReader(tlsConn), messageID: lc.messageID}
+		}
+		// BindRequest: version 3, name, simple authentication.
+		request := appendBER(nil, 0x02, []byte{3})
+		request = appendBER(request, 0x04, []byte(bindDN))
+		request = appendBER(request, 0x80, []byte(password))
+		if err := lc.request(0x60, request, 0x61); err != nil {
+			return fmt.Errorf("bind failed: %w", err)
+		}
+		// UnbindRequest, which has no response.
+		lc.send(0x42, nil)
+		return nil
+	}
+}
+
+// ldapConn is a minimal LDAPv3 client.
+type ldapConn struct {
+	net.Conn
+	reader    *bufio.Reader
+	messageID byte
+}
+
+func (lc *ldapConn) send(tag byte, content []byte) error {
+	lc.messageID++
+	message := appendBER(nil, 0x02, []byte{lc.messageID})
+	message = appendBER(message, tag, content)
+	_, err := lc.Write(appendBER(nil, 0x30, message))
+	return err
+}
+
+// request sends an operation and reads the response with the given tag. A
+// result code other than success is returned as error.
+func (lc *ldapConn) request(tag byte, content []byte, responseTag byte) error {
+	if err := lc.send(tag, content); err != nil {
+		return err
+	}
+	messageTag, message, err := readBER(lc.reader)
+	if err != nil {
+		return err
+	}
+	if messageTag != 0x30 {
+		return errors.New("malformed LDAP message")
+	}
+	elements, err := parseBER(message)
+	if err != nil {
+		return err
+	}
+	if len(elements) < 2 || elements[1].tag != responseTag {
+		return errors.New("unexpected LDAP response")
+	}
+	result, err := parseBER(elements[1].content)
+	if err != nil {
+		return err
+	}
+	if len(result) < 3 || result[0].tag != 0x0A || len(result[0].content) == 0 {
+		return errors.New("malformed LDAP result")
+	}
+	code := 0
+	for _, b := range result[0].content {
+		code = code<<8 | int(b)
+	}
+	if code != 0 {
+		return fmt.Errorf("result code %d: %s", code, result[2].content)
+	}
+	return nil
+}
+
+type berElement struct {
+	tag     byte
+	content []byte
+}
+
+// appendBER appends a BER encoded element with a single byte tag.
+func appendBER(b []byte, tag byte, content []byte) []byte {
+	b = append(b, tag)
+	switch n := len(content); {
+	case n < 0x80:
+		b = append(b, byte(n))
+	case n < 0x100:
+		b = append(b, 0x81, byte(n))
+	case n < 0x10000:
+		b = append(b, 0x82, byte(n>>8), byte(n))
+	default:
+		b = append(b, 0x84, byte(n>>24), byte(n>>16), byte(n>>8), byte(n))
+	}
+	return append(b, content...)
+}
+
+// readBER reads one BER encoded element with a single byte tag.
+func readBER(r io.ByteReader) (byte, []byte, error) {
+	tag, err := r.ReadByte()
+	if err != nil {
+		return 0, nil, err
+	}
+	first, err := r.ReadByte()
+	if err != nil {
+		return 0, nil, err
+	}
+	length := int(first)
+	if first&0x80 != 0 {
+		n := int(first & 0x7F)
+		if n == 0 || n > 4 {
+			return 0, nil, errors.New("unsupported BER length")
+		}
+		length = 0
+		for i := 0; i < n; i++ {
+			b, err := r.ReadByte()
+			if err != nil {
+				return 0, nil, err
+			}
+			length = length<<8 | int(b)
+		}
+	}
+	if length > 1<<20 {
+		return 0, nil, errors.New("BER element too large")
+	}
+	content := make([]byte, length)
+	for i := range content {
+		if content[i], err = r.ReadByte(); err != nil {
+			return 0, nil, err
+		}
+	}
+	return tag, content, nil
+}
+
+// parseBER splits the content of a constructed element into its elements.
+func parseBER(data []byte) ([]berElement, error) {
+	var elements []berElement
+	r := &byteReader{data: data}
+	for len(r.data) > 0 {
+		tag, content, err := readBER(r)
+		if err != nil {
+			return nil, err
+		}
+		elements = append(elements, berElement{tag: tag, content: content})
+	}
+	return elements, nil
+}
+
+type byteReader struct {
+	data []byte
+}
+
+func (r *byteReader) ReadByte() (byte, error) {
+	if len(r.data) == 0 {
+		return 0, io.ErrUnexpectedEOF
+	}
+	b := r.data[0]
+	r.data = r.data[1:]
+	return b, nil
+}
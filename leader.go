@@ -0,0 +1,39 @@
+package healthcheck
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// LeaderElectionCheck returns a Check that fails if hasLeader did not
+// report true for longer than gracePeriod. hasLeader reports whether this
+// instance holds the leadership, or, for followers, whether it observes a
+// current leader; with the leaderelection package of k8s.io/client-go it
+// can be:
+//
+//	func() bool { return elector.IsLeader() }
+//
+// or elector.GetLeader() != "" for followers. Leadership is sampled when
+// the check executes, and the grace period starts with the creation of the
+// check, so that a new instance has time to acquire the lease.
+func LeaderElectionCheck(hasLeader func() bool, gracePeriod time.Duration) func(ctx context.Context) error {
+	var (
+		mtx      sync.Mutex
+		lastSeen = time.Now()
+	)
+	return func(ctx context.Context) error {
+		now := time.Now()
+		mtx.Lock()
+		defer mtx.Unlock()
+		if hasLeader() {
+			lastSeen = now
+			return nil
+		}
+		if lost := now.Sub(lastSeen); lost > gracePeriod {
+			return fmt.Errorf("no leadership for %s > %s", lost.Round(time.Millisecond), gracePeriod)
+		}
+		return nil
+	}
+}
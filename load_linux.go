@@ -0,0 +1,43 @@
+package healthcheck
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"runtime"
+	"strconv"
+	"strings"
+)
+
+// LoadAverageCheck returns a Check that fails if the load average over the
+// window of 1, 5 or 15 minutes exceeds threshold. If perCore is set, the
+// load average is divided by the number of CPUs usable by the process first,
+// so the same threshold applies to hosts of any size.
+func LoadAverageCheck(window int, threshold float64, perCore bool) func(ctx context.Context) error {
+	field := map[int]int{1: 0, 5: 1, 15: 2}
+	return func(ctx context.Context) error {
+		index, ok := field[window]
+		if !ok {
+			return fmt.Errorf("invalid load average window of %d minutes", window)
+		}
+		content, err := os.ReadFile("/proc/loadavg")
+		if err != nil {
+			return err
+		}
+		fields := strings.Fields(string(content))
+		if len(fields) < 3 {
+			return fmt.Errorf("malformed /proc/loadavg")
+		}
+		load, err := strconv.ParseFloat(fields[index], 64)
+		if err != nil {
+			return err
+		}
+		if perCore {
+			load /= float64(runtime.NumCPU())
+		}
+		if load > threshold {
+			return fmt.Errorf("%d minute load average %.2f > %.2f", window, load, threshold)
+		}
+		return nil
+	}
+}
@@ -0,0 +1,14 @@
+//go:build !linux
+
+package healthcheck
+
+import "context"
+
+// LoadAverageCheck returns a Check that fails if the load average over the
+// window of 1, 5 or 15 minutes exceeds threshold. It is only supported on
+// Linux.
+func LoadAverageCheck(window int, threshold float64, perCore bool) func(ctx context.Context) error {
+	return func(ctx context.Context) error {
+		return errUnsupportedPlatform
+	}
+}
@@ -0,0 +1,146 @@
+package healthcheck
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sort"
+	"sync"
+)
+
+// maintenanceState records which checks, or whether the whole system, are in
+// maintenance. It is shared by all checkers of a configuration.
+type maintenanceState struct {
+	mtx    sync.RWMutex
+	system bool
+	checks map[string]bool
+}
+
+func newMaintenanceState() *maintenanceState {
+	return &maintenanceState{checks: map[string]bool{}}
+}
+
+// MaintenanceStatus is the body returned by the maintenance handler (see
+// GetMaintenanceHandler).
+type MaintenanceStatus struct {
+	// System reports whether the whole system is in maintenance.
+	System bool `json:"system"`
+	// Checks holds the names of the checks in maintenance.
+	Checks []string `json:"checks"`
+}
+
+// StartMaintenance puts the named checks, or the whole system if no check is
+// given, into maintenance, e.g. for a planned downtime of a dependency.
+// Checks in maintenance are not executed and report StatusMaintenance, which
+// does not count against the overall status. While the whole system is in
+// maintenance the checks are still executed, but the overall status is
+// StatusMaintenance. It affects all handlers of the configuration, which must
+// have been created by InitChecker.
+func (c *AndictlCheckerConfig) StartMaintenance(checks ...string) {
+	c.setMaintenance(true, checks)
+}
+
+// EndMaintenance ends the maintenance of the named checks, or of the whole
+// system if no check is given.
+func (c *AndictlCheckerConfig) EndMaintenance(checks ...string) {
+	c.setMaintenance(false, checks)
+}
+
+// Maintenance returns which checks, or whether the whole system, are in
+// maintenance.
+func (c *AndictlCheckerConfig) Maintenance() MaintenanceStatus {
+	state := c.maintenanceState()
+	state.mtx.RLock()
+	defer state.mtx.RUnlock()
+	status := MaintenanceStatus{System: state.system, Checks: []string{}}
+	for name := range state.checks {
+		status.Checks = append(status.Checks, name)
+	}
+	sort.Strings(status.Checks)
+	return status
+}
+
+// GetMaintenanceHandler returns an admin handler to control the maintenance
+// mode at runtime. POST starts and DELETE ends the maintenance of the checks
+// given by the query parameter check (e.g., ?check=database&check=cache), or
+// of the whole system without it. All methods respond with the current
+// MaintenanceStatus. The handler must be protected from public access.
+func (c *AndictlCheckerConfig) GetMaintenanceHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+		case http.MethodPost:
+			c.StartMaintenance(r.URL.Query()["check"]...)
+		case http.MethodDelete:
+			c.EndMaintenance(r.URL.Query()["check"]...)
+		default:
+			w.Header().Set("Allow", "GET, POST, DELETE")
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+		json.NewEncoder(w).Encode(c.Maintenance())
+	}
+}
+
+func (c *AndictlCheckerConfig) setMaintenance(enabled bool, checks []string) {
+	state := c.maintenanceState()
+	state.mtx.Lock()
+	defer state.mtx.Unlock()
+	if len(checks) == 0 {
+		state.system = enabled
+	}
+	for _, name := range checks {
+		if enabled {
+			state.checks[name] = true
+		} else {
+			delete(state.checks, name)
+		}
+	}
+}
+
+func (c *AndictlCheckerConfig) maintenanceState() *maintenanceState {
+	if c.maintenance == nil {
+		c.maintenance = newMaintenanceState()
+	}
+	return c.maintenance
+}
+
+// intercept is an interceptor that skips checks in maintenance.
+func (ms *maintenanceState) intercept(next interceptorFunc) interceptorFunc {
+	return func(ctx context.Context, name string, state checkState) checkState {
+		ms.mtx.RLock()
+		inMaintenance := ms.checks[name]
+		ms.mtx.RUnlock()
+		if inMaintenance {
+			return checkState{Status: StatusMaintenance}
+		}
+		return next(ctx, name, state)
+	}
+}
+
+// maintenanceChecker reports the overall status as StatusMaintenance while
+// the whole system is in maintenance.
+type maintenanceChecker struct {
+	engine
+	state *maintenanceState
+}
+
+func (ck *maintenanceChecker) Check(ctx context.Context) CheckerResult {
+	result := ck.engine.Check(ctx)
+	ck.state.apply(&result)
+	return result
+}
+
+// apply sets the status of result to StatusMaintenance while the whole system
+// is in maintenance. It does nothing if ms is nil.
+func (ms *maintenanceState) apply(result *CheckerResult) {
+	if ms == nil {
+		return
+	}
+	ms.mtx.RLock()
+	defer ms.mtx.RUnlock()
+	if ms.system {
+		result.Status = StatusMaintenance
+	}
+}
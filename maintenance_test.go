@@ -0,0 +1,29 @@
+package healthcheck
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestSystemMaintenanceWithTags(t *testing.T) {
+	config := InitChecker()
+	config.MustAdd(WithCheck(Check{Name: "database", Check: func(ctx context.Context) error { return nil }}))
+	config.SetCheckTags("database", "db")
+	config.StartMaintenance()
+	handler := config.GetCheckerHandler()
+
+	for _, target := range []string{"/health", "/health?tags=db"} {
+		rec := httptest.NewRecorder()
+		handler(rec, httptest.NewRequest(http.MethodGet, target, nil))
+		var resp checkerResponse
+		if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+			t.Fatalf("%s: %v", target, err)
+		}
+		if resp.Status != StatusMaintenance {
+			t.Errorf("%s: status %q, want %q", target, resp.Status, StatusMaintenance)
+		}
+	}
+}
@@ -0,0 +1,26 @@
+package healthcheck
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// MongoPingCheck returns a Check that pings a MongoDB deployment with the
+// given read preference. ping is the Ping method of a *mongo.Client of
+// either major version of the official driver:
+//
+//	healthcheck.MongoPingCheck(client.Ping, readpref.Primary(), 2*time.Second)
+//
+// The client is reused, so the check only reports the state of the servers
+// and of its connection pool, without the cost of connecting.
+func MongoPingCheck[R any](ping func(ctx context.Context, readPref R) error, readPref R, timeout time.Duration) func(ctx context.Context) error {
+	return func(ctx context.Context) error {
+		ctx, cancel := withOptionalTimeout(ctx, timeout)
+		defer cancel()
+		if ping == nil {
+			return fmt.Errorf("ping is nil")
+		}
+		return ping(ctx, readPref)
+	}
+}
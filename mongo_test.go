@@ -0,0 +1,35 @@
+package healthcheck
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// readPref stands in for *readpref.ReadPref of the MongoDB driver.
+type readPref struct{ mode string }
+
+func TestMongoPingCheck(t *testing.T) {
+	var got *readPref
+	var deadline bool
+	errDown := errors.New("server selection timeout")
+	ping := func(ctx context.Context, rp *readPref) error {
+		got = rp
+		_, deadline = ctx.Deadline()
+		if rp.mode != "primary" {
+			return errDown
+		}
+		return nil
+	}
+	primary := &readPref{mode: "primary"}
+	if err := MongoPingCheck(ping, primary, time.Second)(context.Background()); err != nil {
+		t.Errorf("unexpected error %v", err)
+	}
+	if got != primary || !deadline {
+		t.Errorf("ping called with %v, deadline %v", got, deadline)
+	}
+	if err := MongoPingCheck(ping, &readPref{mode: "secondary"}, 0)(context.Background()); !errors.Is(err, errDown) {
+		t.Errorf("error %v, want %v", err, errDown)
+	}
+}
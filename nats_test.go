@@ -0,0 +1,34 @@
+package healthcheck
+
+import (
+	"bufio"
+	"net"
+	"strings"
+	"testing"
+)
+
+func TestNATSNextMsg(t *testing.T) {
+	tests := []struct {
+		input   string
+		payload string
+		ok      bool
+	}{
+		{"MSG inbox 1 5\r\nhello\r\n", "hello", true},
+		{"MSG inbox 1 reply 0\r\n\r\n", "", true},
+		{"MSG inbox 1 -1\r\nhello\r\n", "", false},
+		{"MSG inbox 1 -3\r\nhello\r\n", "", false},
+		{"MSG inbox 1 2097152\r\n", "", false},
+		{"MSG inbox 1 x\r\n", "", false},
+		{"-ERR 'Permissions Violation'\r\n", "", false},
+	}
+	for _, tt := range tests {
+		client, server := net.Pipe()
+		nc := &natsConn{conn: client, reader: bufio.NewReader(strings.NewReader(tt.input))}
+		_, payload, err := nc.nextMsg()
+		if (err == nil) != tt.ok || string(payload) != tt.payload {
+			t.Errorf("nextMsg(%q) = %q, %v", tt.input, payload, err)
+		}
+		client.Close()
+		server.Close()
+	}
+}
@@ -0,0 +1,132 @@
+package healthcheck
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"time"
+)
+
+// InterfaceCheck returns a Check that verifies the named network interface is
+// up and has at least one address assigned. If requireCarrier is set, the
+// check also fails when the interface has no carrier (e.g., the cable is
+// unplugged or the VPN tunnel is down). Carrier detection is only supported on
+// Linux.
+func InterfaceCheck(name string, requireCarrier bool) func(ctx context.Context) error {
+	return func(ctx context.Context) error {
+		iface, err := net.InterfaceByName(name)
+		if err != nil {
+			return err
+		}
+		if iface.Flags&net.FlagUp == 0 {
+			return fmt.Errorf("interface %s is down", name)
+		}
+		addrs, err := iface.Addrs()
+		if err != nil {
+			return err
+		}
+		if len(addrs) < 1 {
+			return fmt.Errorf("interface %s has no address assigned", name)
+		}
+		if requireCarrier {
+			carrier, err := interfaceCarrier(name)
+			if err != nil {
+				return err
+			}
+			if !carrier {
+				return fmt.Errorf("interface %s has no carrier", name)
+			}
+		}
+		return nil
+	}
+}
+
+// UDPCheck returns a Check that sends payload as datagram to addr. If expect
+// is not nil, the check waits for a response and fails unless the response
+// starts with expect; an empty expect accepts any response. As UDP is
+// connectionless, an unreachable endpoint is only detected while waiting for
+// a response.
+func UDPCheck(addr string, payload, expect []byte, timeout time.Duration) func(ctx context.Context) error {
+	return func(ctx context.Context) error {
+		ctx, cancel := withOptionalTimeout(ctx, timeout)
+		defer cancel()
+		var dialer net.Dialer
+		conn, err := dialer.DialContext(ctx, "udp", addr)
+		if err != nil {
+			return err
+		}
+		defer conn.Close()
+		if deadline, ok := ctx.Deadline(); ok {
+			conn.SetDeadline(deadline)
+		}
+		if _, err := conn.Write(payload); err != nil {
+			return err
+		}
+		if expect == nil {
+			return nil
+		}
+		response := make([]byte, 64<<10)
+		n, err := conn.Read(response)
+		if err != nil {
+			return err
+		}
+		if response = response[:n]; !bytes.HasPrefix(response, expect) {
+			if len(response) > 64 {
+				response = response[:64]
+			}
+			return fmt.Errorf("unexpected response %q", response)
+		}
+		return nil
+	}
+}
+
+// UnixSocketCheck returns a Check that verifies connectivity to the unix
+// domain socket at path.
+func UnixSocketCheck(path string, timeout time.Duration) func(ctx context.Context) error {
+	return func(ctx context.Context) error {
+		ctx, cancel := withOptionalTimeout(ctx, timeout)
+		defer cancel()
+		var dialer net.Dialer
+		conn, err := dialer.DialContext(ctx, "unix", path)
+		if err != nil {
+			return err
+		}
+		return conn.Close()
+	}
+}
+
+// UnixSocketHTTPCheck returns a Check that performs an HTTP GET request for
+// requestPath (e.g., "/_ping" for the Docker daemon) over the unix domain
+// socket at path and fails on a non-200 status code.
+func UnixSocketHTTPCheck(path, requestPath string, timeout time.Duration) func(ctx context.Context) error {
+	client := http.Client{
+		Timeout: timeout,
+		Transport: &http.Transport{
+			DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+				var dialer net.Dialer
+				return dialer.DialContext(ctx, "unix", path)
+			},
+		},
+		// never follow redirects
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+	return func(ctx context.Context) error {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, "http://localhost"+requestPath, nil)
+		if err != nil {
+			return err
+		}
+		resp, err := client.Do(req)
+		if err != nil {
+			return err
+		}
+		resp.Body.Close()
+		if resp.StatusCode != 200 {
+			return fmt.Errorf("returned status %d", resp.StatusCode)
+		}
+		return nil
+	}
+}
@@ -0,0 +1,234 @@
+package healthcheck
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+	"unsafe"
+)
+
+func interfaceCarrier(name string) (bool, error) {
+	content, err := os.ReadFile(filepath.Join("/sys/class/net", name, "carrier"))
+	if err != nil {
+		return false, err
+	}
+	return strings.TrimSpace(string(content)) == "1", nil
+}
+
+// DefaultGatewayCheck returns a Check that verifies an IPv4 default route
+// exists and its gateway answers within the specified timeout. This reports
+// a host or pod without network distinctly from the timeouts of the
+// individual dependency checks.
+//
+// The gateway answers if its neighbor entry is REACHABLE, i.e., the kernel
+// has recently confirmed it, or if it rejects the datagrams the check sends
+// with an ICMP error. A STALE entry is only probed again after the
+// delay_first_probe_time of the interface (5s by default), so the timeout
+// should exceed it for gateways that do not send ICMP errors. Static
+// (PERMANENT or NOARP) entries cannot be probed and are accepted as is.
+func DefaultGatewayCheck(timeout time.Duration) func(ctx context.Context) error {
+	return func(ctx context.Context) error {
+		ctx, cancel := withOptionalTimeout(ctx, timeout)
+		defer cancel()
+		iface, gateway, err := defaultGateway()
+		if err != nil {
+			return err
+		}
+		link, err := net.InterfaceByName(iface)
+		if err != nil {
+			return err
+		}
+		// Sending datagrams makes the kernel resolve the gateway address, and
+		// probe it again if the entry is stale.
+		conn, err := net.DialUDP("udp4", nil, &net.UDPAddr{IP: gateway, Port: 9})
+		if err != nil {
+			return err
+		}
+		defer conn.Close()
+		var state uint16
+		buf := make([]byte, 1)
+		for {
+			// An ICMP error for an earlier datagram fails the next operation.
+			if _, err := conn.Write([]byte{0}); errors.Is(err, syscall.ECONNREFUSED) {
+				return nil
+			} else if err != nil {
+				return err
+			}
+			if state, err = neighborState(link.Index, gateway); err != nil {
+				return err
+			}
+			if state&(nudReachable|nudNoARP|nudPermanent) != 0 {
+				return nil
+			}
+			conn.SetReadDeadline(time.Now().Add(50 * time.Millisecond))
+			if _, err := conn.Read(buf); errors.Is(err, syscall.ECONNREFUSED) {
+				return nil
+			}
+			if ctx.Err() != nil {
+				return fmt.Errorf("gateway %s on %s does not answer (neighbor state %s)", gateway, iface, neighborStateName(state))
+			}
+		}
+	}
+}
+
+// defaultGateway returns the interface and gateway of the IPv4 default route
+// from /proc/net/route.
+func defaultGateway() (string, net.IP, error) {
+	f, err := os.Open("/proc/net/route")
+	if err != nil {
+		return "", nil, err
+	}
+	defer f.Close()
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 4 || fields[1] != "00000000" {
+			continue
+		}
+		flags, err := strconv.ParseUint(fields[3], 16, 16)
+		if err != nil || flags&0x3 != 0x3 { // RTF_UP | RTF_GATEWAY
+			continue
+		}
+		raw, err := hex.DecodeString(fields[2])
+		if err != nil || len(raw) != 4 {
+			continue
+		}
+		gateway := make(net.IP, 4)
+		binary.BigEndian.PutUint32(gateway, binary.LittleEndian.Uint32(raw))
+		return fields[0], gateway, nil
+	}
+	if err := scanner.Err(); err != nil {
+		return "", nil, err
+	}
+	return "", nil, fmt.Errorf("no default route")
+}
+
+// Neighbor states (NUD_*) of the kernel accepted by DefaultGatewayCheck.
+const (
+	nudReachable = 0x02
+	nudNoARP     = 0x40
+	nudPermanent = 0x80
+)
+
+// neighborState returns the state of the neighbor entry for ip on the
+// interface with index ifindex, or zero if there is none.
+func neighborState(ifindex int, ip net.IP) (uint16, error) {
+	data, err := syscall.NetlinkRIB(syscall.RTM_GETNEIGH, syscall.AF_INET)
+	if err != nil {
+		return 0, err
+	}
+	msgs, err := syscall.ParseNetlinkMessage(data)
+	if err != nil {
+		return 0, err
+	}
+	return findNeighborState(msgs, ifindex, ip), nil
+}
+
+// findNeighborState returns the state of the neighbor entry for ip on the
+// interface with index ifindex in the RTM_NEWNEIGH messages msgs. Each
+// message holds a struct ndmsg followed by route attributes.
+func findNeighborState(msgs []syscall.NetlinkMessage, ifindex int, ip net.IP) uint16 {
+	for _, msg := range msgs {
+		if msg.Header.Type != syscall.RTM_NEWNEIGH || len(msg.Data) < 12 {
+			continue
+		}
+		if int(int32(nativeEndian.Uint32(msg.Data[4:8]))) != ifindex {
+			continue
+		}
+		state := nativeEndian.Uint16(msg.Data[8:10])
+		attrs := msg.Data[12:]
+		for len(attrs) >= 4 {
+			length := int(nativeEndian.Uint16(attrs[0:2]))
+			if length < 4 || length > len(attrs) {
+				break
+			}
+			if nativeEndian.Uint16(attrs[2:4]) == 1 && net.IP(attrs[4:length]).Equal(ip) { // NDA_DST
+				return state
+			}
+			length = (length + 3) &^ 3
+			if length > len(attrs) {
+				break
+			}
+			attrs = attrs[length:]
+		}
+	}
+	return 0
+}
+
+// neighborStateName returns the names of the NUD_* flags set in state.
+func neighborStateName(state uint16) string {
+	names := []string{"INCOMPLETE", "REACHABLE", "STALE", "DELAY", "PROBE", "FAILED", "NOARP", "PERMANENT"}
+	var set []string
+	for i, name := range names {
+		if state&(1<<i) != 0 {
+			set = append(set, name)
+		}
+	}
+	if len(set) == 0 {
+		return "NONE"
+	}
+	return strings.Join(set, "|")
+}
+
+// nativeEndian is the byte order of netlink messages.
+var nativeEndian binary.ByteOrder = func() binary.ByteOrder {
+	x := uint16(1)
+	if *(*byte)(unsafe.Pointer(&x)) == 1 {
+		return binary.LittleEndian
+	}
+	return binary.BigEndian
+}()
+
+// ListeningPortCheck returns a Check that fails unless a TCP socket of the
+// network namespace of the process (i.e., of the host or the container) is
+// listening on port, on any address. Unlike dialing the port, this also
+// covers servers bound to addresses that are not reachable locally.
+func ListeningPortCheck(port int) func(ctx context.Context) error {
+	return func(ctx context.Context) error {
+		for _, path := range []string{"/proc/net/tcp", "/proc/net/tcp6"} {
+			listening, err := tcpListening(path, port)
+			if err != nil && !os.IsNotExist(err) {
+				return err
+			}
+			if listening {
+				return nil
+			}
+		}
+		return fmt.Errorf("no socket listening on port %d", port)
+	}
+}
+
+// tcpListening reports whether the socket table at path, such as
+// /proc/net/tcp, holds a socket in LISTEN state on port.
+func tcpListening(path string, port int) (bool, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return false, err
+	}
+	defer f.Close()
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 4 || fields[3] != "0A" { // TCP_LISTEN
+			continue
+		}
+		_, localPort, ok := strings.Cut(fields[1], ":")
+		if !ok {
+			continue
+		}
+		if value, err := strconv.ParseUint(localPort, 16, 16); err == nil && int(value) == port {
+			return true, nil
+		}
+	}
+	return false, scanner.Err()
+}
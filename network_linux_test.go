@@ -0,0 +1,50 @@
+package healthcheck
+
+import (
+	"net"
+	"syscall"
+	"testing"
+)
+
+func TestFindNeighborState(t *testing.T) {
+	neighbor := func(ifindex int32, state uint16, dst net.IP) syscall.NetlinkMessage {
+		data := make([]byte, 12, 20)
+		nativeEndian.PutUint32(data[4:8], uint32(ifindex))
+		nativeEndian.PutUint16(data[8:10], state)
+		attr := make([]byte, 4)
+		nativeEndian.PutUint16(attr[0:2], uint16(4+len(dst)))
+		nativeEndian.PutUint16(attr[2:4], 1) // NDA_DST
+		data = append(append(data, attr...), dst...)
+		return syscall.NetlinkMessage{Header: syscall.NlMsghdr{Type: syscall.RTM_NEWNEIGH}, Data: data}
+	}
+	gateway := net.IPv4(192, 0, 2, 1).To4()
+	msgs := []syscall.NetlinkMessage{
+		neighbor(2, nudReachable, gateway),
+		neighbor(3, nudReachable, net.IPv4(192, 0, 2, 2).To4()),
+		neighbor(3, 0x04, gateway), // NUD_STALE
+		{Header: syscall.NlMsghdr{Type: syscall.RTM_NEWNEIGH}, Data: []byte{1, 2}},
+	}
+	tests := []struct {
+		ifindex int
+		want    uint16
+	}{
+		{2, nudReachable},
+		{3, 0x04},
+		{4, 0},
+	}
+	for _, tt := range tests {
+		if got := findNeighborState(msgs, tt.ifindex, gateway); got != tt.want {
+			t.Errorf("findNeighborState(%d) = %#x, want %#x", tt.ifindex, got, tt.want)
+		}
+	}
+	if name := neighborStateName(0x04); name != "STALE" {
+		t.Errorf("neighborStateName(0x04) = %q, want STALE", name)
+	}
+}
+
+func TestNeighborState(t *testing.T) {
+	// The dump must succeed even without a default route or neighbors.
+	if _, err := neighborState(1, net.IPv4(127, 0, 0, 1)); err != nil {
+		t.Fatal(err)
+	}
+}
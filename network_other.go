@@ -0,0 +1,28 @@
+//go:build !linux
+
+package healthcheck
+
+import (
+	"context"
+	"time"
+)
+
+func interfaceCarrier(name string) (bool, error) {
+	return false, errUnsupportedPlatform
+}
+
+// DefaultGatewayCheck returns a Check that verifies an IPv4 default route
+// exists and its gateway answers. It is only supported on Linux.
+func DefaultGatewayCheck(timeout time.Duration) func(ctx context.Context) error {
+	return func(ctx context.Context) error {
+		return errUnsupportedPlatform
+	}
+}
+
+// ListeningPortCheck returns a Check that fails unless a TCP socket is
+// listening on port. It is only supported on Linux.
+func ListeningPortCheck(port int) func(ctx context.Context) error {
+	return func(ctx context.Context) error {
+		return errUnsupportedPlatform
+	}
+}
@@ -0,0 +1,157 @@
+package healthcheck
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ObjectStorageOptions configures the connection to an S3-compatible object
+// storage.
+type ObjectStorageOptions struct {
+	// Endpoint is the base URL of the service, such as
+	// "https://s3.eu-west-1.amazonaws.com" or "http://minio:9000".
+	Endpoint string
+	// Region used to sign the requests, e.g. "eu-west-1". Default is
+	// "us-east-1".
+	Region string
+	// Bucket is the name of the bucket to check.
+	Bucket string
+	// Credentials used to sign the requests.
+	Credentials AWSCredentials
+	// PathStyle addresses the bucket in the URL path rather than in the host
+	// name, as required by most S3-compatible services such as MinIO.
+	PathStyle bool
+	// Timeout for the whole check. If zero, the deadline of the check
+	// context applies.
+	Timeout time.Duration
+}
+
+// ObjectStorageCheck returns a Check that sends a HeadBucket request to the
+// bucket. This verifies that the endpoint is reachable, the credentials are
+// valid and grant access to the bucket, without transferring any object.
+func ObjectStorageCheck(options ObjectStorageOptions) func(ctx context.Context) error {
+	client := &objectStorageClient{options: options, client: &http.Client{}}
+	return func(ctx context.Context) error {
+		ctx, cancel := withOptionalTimeout(ctx, options.Timeout)
+		defer cancel()
+		if _, err := client.do(ctx, http.MethodHead, "", nil, nil); err != nil {
+			return fmt.Errorf("bucket %s: %w", options.Bucket, err)
+		}
+		return nil
+	}
+}
+
+// ObjectStorageCanaryCheck returns a BucketCanaryCheck for the bucket of
+// options.
+func ObjectStorageCanaryCheck(options ObjectStorageOptions, prefix string) func(ctx context.Context) error {
+	client := &objectStorageClient{options: options, client: &http.Client{}}
+	return BucketCanaryCheck(client, prefix, options.Timeout)
+}
+
+// ObjectStore is the subset of an object storage client used by
+// BucketCanaryCheck. It can be implemented on top of any SDK, e.g. for
+// Google Cloud Storage or Azure Blob Storage.
+type ObjectStore interface {
+	Put(ctx context.Context, key string, content []byte) error
+	Get(ctx context.Context, key string) ([]byte, error)
+	Delete(ctx context.Context, key string) error
+}
+
+// BucketCanaryCheck returns a Check that writes a small object below prefix
+// (e.g., "healthcheck/"), reads it back, compares its content and deletes it
+// again. Unlike a metadata request, this verifies credentials, permissions
+// and the data path end to end. Keys have the format
+// "<prefix>healthcheck-<timestamp>", so that objects left behind by a failed
+// delete can be expired with a lifecycle rule on the prefix. A zero timeout
+// leaves the deadline of the check context.
+func BucketCanaryCheck(store ObjectStore, prefix string, timeout time.Duration) func(ctx context.Context) error {
+	return func(ctx context.Context) error {
+		ctx, cancel := withOptionalTimeout(ctx, timeout)
+		defer cancel()
+		payload := make([]byte, 64)
+		if _, err := rand.Read(payload); err != nil {
+			return err
+		}
+		key := prefix + "healthcheck-" + strconv.FormatInt(time.Now().UnixNano(), 36)
+		if err := store.Put(ctx, key, payload); err != nil {
+			return fmt.Errorf("writing %s: %w", key, err)
+		}
+		content, err := store.Get(ctx, key)
+		if err == nil && !bytes.Equal(content, payload) {
+			err = fmt.Errorf("content mismatch")
+		}
+		if err != nil {
+			store.Delete(ctx, key)
+			return fmt.Errorf("reading %s: %w", key, err)
+		}
+		if err := store.Delete(ctx, key); err != nil {
+			return fmt.Errorf("deleting %s: %w", key, err)
+		}
+		return nil
+	}
+}
+
+type objectStorageClient struct {
+	options ObjectStorageOptions
+	client  *http.Client
+}
+
+// do performs a signed request on the object key of the bucket (or on the
+// bucket itself for an empty key) and returns the response body.
+func (c *objectStorageClient) do(ctx context.Context, method, key string, query url.Values, body []byte) ([]byte, error) {
+	endpoint, err := url.Parse(c.options.Endpoint)
+	if err != nil {
+		return nil, err
+	}
+	if c.options.PathStyle {
+		endpoint.Path = strings.TrimSuffix(endpoint.Path, "/") + "/" + c.options.Bucket + "/" + key
+	} else {
+		endpoint.Host = c.options.Bucket + "." + endpoint.Host
+		endpoint.Path = "/" + key
+	}
+	endpoint.RawQuery = query.Encode()
+	req, err := http.NewRequestWithContext(ctx, method, endpoint.String(), bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	region := c.options.Region
+	if region == "" {
+		region = "us-east-1"
+	}
+	signAWSRequest(req, body, region, "s3", c.options.Credentials)
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	content, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return nil, fmt.Errorf("returned status %d", resp.StatusCode)
+	}
+	return content, nil
+}
+
+func (c *objectStorageClient) Put(ctx context.Context, key string, content []byte) error {
+	_, err := c.do(ctx, http.MethodPut, key, nil, content)
+	return err
+}
+
+func (c *objectStorageClient) Get(ctx context.Context, key string) ([]byte, error) {
+	return c.do(ctx, http.MethodGet, key, nil, nil)
+}
+
+func (c *objectStorageClient) Delete(ctx context.Context, key string) error {
+	_, err := c.do(ctx, http.MethodDelete, key, nil, nil)
+	return err
+}
@@ -0,0 +1,100 @@
+package healthcheck
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// OIDCDiscoveryCheck returns a Check that fetches the OpenID Connect
+// discovery document of issuer (e.g., "https://accounts.example.com") and
+// the JSON Web Key Set it references, and fails unless both are valid and
+// the key set holds at least one usable key. A broken key set makes every
+// token validation fail while the identity provider itself is up.
+func OIDCDiscoveryCheck(issuer string, timeout time.Duration) func(ctx context.Context) error {
+	client := &http.Client{Timeout: timeout}
+	return func(ctx context.Context) error {
+		ctx, cancel := withOptionalTimeout(ctx, timeout)
+		defer cancel()
+		var discovery struct {
+			Issuer  string `json:"issuer"`
+			JWKSURI string `json:"jwks_uri"`
+		}
+		if err := getJSON(ctx, client, strings.TrimSuffix(issuer, "/")+"/.well-known/openid-configuration", &discovery); err != nil {
+			return fmt.Errorf("discovery: %w", err)
+		}
+		if strings.TrimSuffix(discovery.Issuer, "/") != strings.TrimSuffix(issuer, "/") {
+			return fmt.Errorf("discovery: issuer %q does not match %q", discovery.Issuer, issuer)
+		}
+		if discovery.JWKSURI == "" {
+			return errors.New("discovery: no jwks_uri")
+		}
+		var keySet struct {
+			Keys []map[string]interface{} `json:"keys"`
+		}
+		if err := getJSON(ctx, client, discovery.JWKSURI, &keySet); err != nil {
+			return fmt.Errorf("JWKS: %w", err)
+		}
+		if len(keySet.Keys) == 0 {
+			return errors.New("JWKS: no keys")
+		}
+		for i, key := range keySet.Keys {
+			if err := validateJWK(key); err != nil {
+				return fmt.Errorf("JWKS: key %d: %w", i, err)
+			}
+		}
+		return nil
+	}
+}
+
+// validateJWK verifies that key holds the parameters of its key type.
+func validateJWK(key map[string]interface{}) error {
+	var params []string
+	switch kty, _ := key["kty"].(string); kty {
+	case "RSA":
+		params = []string{"n", "e"}
+	case "EC":
+		params = []string{"crv", "x", "y"}
+	case "OKP":
+		params = []string{"crv", "x"}
+	case "":
+		return errors.New("no key type")
+	default:
+		return fmt.Errorf("unsupported key type %q", kty)
+	}
+	for _, param := range params {
+		value, _ := key[param].(string)
+		if value == "" {
+			return fmt.Errorf("missing parameter %q", param)
+		}
+		if param == "crv" {
+			continue
+		}
+		if _, err := base64.RawURLEncoding.DecodeString(value); err != nil {
+			return fmt.Errorf("invalid parameter %q: %w", param, err)
+		}
+	}
+	return nil
+}
+
+// getJSON fetches url and decodes the JSON response into v.
+func getJSON(ctx context.Context, client *http.Client, url string, v interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != 200 {
+		return fmt.Errorf("returned status %d", resp.StatusCode)
+	}
+	return json.NewDecoder(resp.Body).Decode(v)
+}
@@ -0,0 +1,70 @@
+package healthcheck
+
+import (
+	"context"
+	"sync"
+)
+
+// overrideState holds the status forced by SetOverride. It is shared by all
+// checker handlers of a configuration.
+type overrideState struct {
+	mtx    sync.RWMutex
+	status AvailabilityStatus
+}
+
+func (so *overrideState) get() AvailabilityStatus {
+	so.mtx.RLock()
+	defer so.mtx.RUnlock()
+	return so.status
+}
+
+func (so *overrideState) set(status AvailabilityStatus) {
+	so.mtx.Lock()
+	defer so.mtx.Unlock()
+	so.status = status
+}
+
+// SetOverride forces the checker handlers to report status, e.g. StatusDown
+// to drain an instance or StatusUp to ignore a known false positive, until
+// ClearOverride is called. The checks are still executed and reported, and
+// the response carries an override field so the forced status is obvious.
+// It affects all checker handlers of the configuration, which must have been
+// created by InitChecker, but not Status or the score handler.
+func (c *AndictlCheckerConfig) SetOverride(status AvailabilityStatus) {
+	c.overrideState().set(status)
+}
+
+// ClearOverride removes the status set by SetOverride.
+func (c *AndictlCheckerConfig) ClearOverride() {
+	c.overrideState().set("")
+}
+
+func (c *AndictlCheckerConfig) overrideState() *overrideState {
+	if c.override == nil {
+		c.override = &overrideState{}
+	}
+	return c.override
+}
+
+// overrideChecker replaces the overall status with the override, if any.
+type overrideChecker struct {
+	engine
+	state *overrideState
+}
+
+func (ck *overrideChecker) Check(ctx context.Context) CheckerResult {
+	result := ck.engine.Check(ctx)
+	ck.state.apply(&result)
+	return result
+}
+
+// apply sets the status of result to the override, if any. It does nothing
+// if so is nil.
+func (so *overrideState) apply(result *CheckerResult) {
+	if so == nil {
+		return
+	}
+	if status := so.get(); status != "" {
+		result.Status = status
+	}
+}
@@ -0,0 +1,42 @@
+package healthcheck
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestOverrideWithTags(t *testing.T) {
+	config := InitChecker()
+	config.MustAdd(WithCheck(Check{Name: "database", Check: func(ctx context.Context) error { return nil }}))
+	config.SetCheckTags("database", "db")
+	config.SetOverride(StatusDown)
+	handler := config.GetCheckerHandler()
+
+	for _, target := range []string{"/health", "/health?tags=db"} {
+		rec := httptest.NewRecorder()
+		handler(rec, httptest.NewRequest(http.MethodGet, target, nil))
+		if rec.Code != http.StatusServiceUnavailable {
+			t.Errorf("%s: status code %d, want %d", target, rec.Code, http.StatusServiceUnavailable)
+		}
+		var resp checkerResponse
+		if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+			t.Fatalf("%s: %v", target, err)
+		}
+		if resp.Status != StatusDown || resp.Override != StatusDown {
+			t.Errorf("%s: status %q, override %q, want %q", target, resp.Status, resp.Override, StatusDown)
+		}
+		if resp.Details["database"].Status != StatusUp {
+			t.Errorf("%s: database status %q, want %q", target, resp.Details["database"].Status, StatusUp)
+		}
+	}
+
+	config.ClearOverride()
+	rec := httptest.NewRecorder()
+	handler(rec, httptest.NewRequest(http.MethodGet, "/health?tags=db", nil))
+	if rec.Code != http.StatusOK {
+		t.Errorf("after ClearOverride: status code %d, want %d", rec.Code, http.StatusOK)
+	}
+}
@@ -0,0 +1,10 @@
+package healthcheck
+
+import (
+	"fmt"
+	"runtime"
+)
+
+// errUnsupportedPlatform is returned by checks that cannot inspect the system
+// they are running on.
+var errUnsupportedPlatform = fmt.Errorf("check is not supported on %s", runtime.GOOS)
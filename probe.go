@@ -0,0 +1,67 @@
+package healthcheck
+
+import "net/http"
+
+// Probe is a kind of Kubernetes container probe.
+type Probe int
+
+const (
+	// ProbeReadiness decides whether the instance receives traffic. A
+	// failing readiness probe removes it from rotation.
+	ProbeReadiness Probe = iota
+	// ProbeLiveness decides whether the instance is restarted. It should only
+	// contain checks that a restart can fix, never downstream dependencies.
+	ProbeLiveness
+	// ProbeStartup holds off the other probes until the instance has
+	// started.
+	ProbeStartup
+)
+
+// SetCheckProbes assigns the named check to probes, replacing its previous
+// assignment. Checks without an explicit assignment belong to the readiness
+// and startup probes, so that dependencies never restart the instance unless
+// configured to.
+func (c *AndictlCheckerConfig) SetCheckProbes(name string, probes ...Probe) {
+	if c.probes == nil {
+		c.probes = map[string][]Probe{}
+	}
+	c.probes[name] = probes
+}
+
+// GetLivenessHandler returns a handler like GetCheckerHandler that only
+// evaluates the checks of the liveness probe. Without such checks it always
+// reports the system as up.
+func (c AndictlCheckerConfig) GetLivenessHandler() http.HandlerFunc {
+	return c.probeHandler(ProbeLiveness)
+}
+
+// GetReadinessHandler returns a handler like GetCheckerHandler that only
+// evaluates the checks of the readiness probe.
+func (c AndictlCheckerConfig) GetReadinessHandler() http.HandlerFunc {
+	return c.probeHandler(ProbeReadiness)
+}
+
+// GetStartupHandler returns a handler like GetCheckerHandler that only
+// evaluates the checks of the startup probe.
+func (c AndictlCheckerConfig) GetStartupHandler() http.HandlerFunc {
+	return c.probeHandler(ProbeStartup)
+}
+
+func (c AndictlCheckerConfig) probeHandler(probe Probe) http.HandlerFunc {
+	probes := c.probes
+	c.include = func(name string) bool {
+		assigned, ok := probes[name]
+		if !ok {
+			return probe != ProbeLiveness
+		}
+		for _, p := range assigned {
+			if p == probe {
+				return true
+			}
+		}
+		return false
+	}
+	// The results of a probe do not describe the whole system.
+	c.snapshots = nil
+	return c.GetCheckerHandler()
+}
@@ -0,0 +1,174 @@
+package healthcheck
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+)
+
+// ProcessMemoryCheck returns a Check that fails if the resident set size of
+// the process exceeds maxBytes. Unlike the memory statistics of the Go
+// runtime, this includes memory allocated by cgo and the runtime itself.
+func ProcessMemoryCheck(maxBytes uint64) func(ctx context.Context) error {
+	return func(ctx context.Context) error {
+		status, err := readProcFields("/proc/self/status")
+		if err != nil {
+			return err
+		}
+		rss, ok := status["VmRSS"]
+		if !ok {
+			return fmt.Errorf("resident set size not available")
+		}
+		if rss *= 1024; rss > maxBytes {
+			return fmt.Errorf("resident set size %d bytes > %d bytes", rss, maxBytes)
+		}
+		return nil
+	}
+}
+
+// ThreadCountCheck returns a Check that fails if the process runs more than
+// threshold OS threads. Goroutines blocked in system calls or cgo calls each
+// occupy a thread, so this catches thread explosions that
+// GoroutineCountCheck does not.
+func ThreadCountCheck(threshold int) func(ctx context.Context) error {
+	return func(ctx context.Context) error {
+		status, err := readProcFields("/proc/self/status")
+		if err != nil {
+			return err
+		}
+		threads, ok := status["Threads"]
+		if !ok {
+			return fmt.Errorf("thread count not available")
+		}
+		if threads > uint64(threshold) {
+			return fmt.Errorf("too many OS threads (%d > %d)", threads, threshold)
+		}
+		return nil
+	}
+}
+
+// FileDescriptorCheck returns a Check that fails if the process uses more
+// than thresholdPercent of the file descriptors allowed by its soft limit
+// (RLIMIT_NOFILE), before file descriptor exhaustion makes operations fail
+// with "too many open files".
+func FileDescriptorCheck(thresholdPercent float64) func(ctx context.Context) error {
+	return func(ctx context.Context) error {
+		var limit syscall.Rlimit
+		if err := syscall.Getrlimit(syscall.RLIMIT_NOFILE, &limit); err != nil {
+			return err
+		}
+		dir, err := os.Open("/proc/self/fd")
+		if err != nil {
+			return err
+		}
+		names, err := dir.Readdirnames(-1)
+		dir.Close()
+		if err != nil {
+			return err
+		}
+		// Do not count the descriptor used to read the directory.
+		open := len(names) - 1
+		usedPercent := float64(open) / float64(limit.Cur) * 100
+		if usedPercent > thresholdPercent {
+			return fmt.Errorf("%d of %d file descriptors in use (%.1f%% > %.1f%%)", open, limit.Cur, usedPercent, thresholdPercent)
+		}
+		return nil
+	}
+}
+
+// ProcessCheck returns a Check that fails unless a process with the given
+// name is running. The name is matched against the executable name of the
+// process and the base name of its first argument. If rejectZombie is set,
+// processes that exited but were not reaped by their parent do not count.
+func ProcessCheck(name string, rejectZombie bool) func(ctx context.Context) error {
+	return func(ctx context.Context) error {
+		entries, err := os.ReadDir("/proc")
+		if err != nil {
+			return err
+		}
+		zombie := false
+		for _, entry := range entries {
+			pid, err := strconv.Atoi(entry.Name())
+			if err != nil || !processNameMatches(pid, name) {
+				continue
+			}
+			state, err := processState(pid)
+			if err != nil {
+				// The process exited in the meantime.
+				continue
+			}
+			if state == 'Z' && rejectZombie {
+				zombie = true
+				continue
+			}
+			return nil
+		}
+		if zombie {
+			return fmt.Errorf("process %s is a zombie", name)
+		}
+		return fmt.Errorf("process %s is not running", name)
+	}
+}
+
+// PIDFileCheck returns a Check that fails unless the process whose PID is
+// stored in the file at path is running. If rejectZombie is set, a process
+// that exited but was not reaped by its parent counts as not running.
+func PIDFileCheck(path string, rejectZombie bool) func(ctx context.Context) error {
+	return func(ctx context.Context) error {
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		pid, err := strconv.Atoi(strings.TrimSpace(string(content)))
+		if err != nil || pid <= 0 {
+			return fmt.Errorf("invalid PID file %s", path)
+		}
+		state, err := processState(pid)
+		if os.IsNotExist(err) {
+			return fmt.Errorf("process %d is not running", pid)
+		}
+		if err != nil {
+			return err
+		}
+		if state == 'Z' && rejectZombie {
+			return fmt.Errorf("process %d is a zombie", pid)
+		}
+		return nil
+	}
+}
+
+func processNameMatches(pid int, name string) bool {
+	dir := filepath.Join("/proc", strconv.Itoa(pid))
+	if comm, err := os.ReadFile(filepath.Join(dir, "comm")); err == nil && strings.TrimSpace(string(comm)) == name {
+		return true
+	}
+	// comm is truncated to 15 characters.
+	cmdline, err := os.ReadFile(filepath.Join(dir, "cmdline"))
+	if err != nil {
+		return false
+	}
+	if i := bytes.IndexByte(cmdline, 0); i >= 0 {
+		cmdline = cmdline[:i]
+	}
+	return len(cmdline) > 0 && filepath.Base(string(cmdline)) == name
+}
+
+// processState returns the state of the process from /proc/<pid>/stat, e.g.
+// 'R' (running), 'S' (sleeping) or 'Z' (zombie).
+func processState(pid int) (byte, error) {
+	stat, err := os.ReadFile(filepath.Join("/proc", strconv.Itoa(pid), "stat"))
+	if err != nil {
+		return 0, err
+	}
+	// The executable name in parentheses may contain spaces.
+	i := bytes.LastIndexByte(stat, ')')
+	if i < 0 || i+2 >= len(stat) {
+		return 0, fmt.Errorf("malformed stat of process %d", pid)
+	}
+	return stat[i+2], nil
+}
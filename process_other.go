@@ -0,0 +1,46 @@
+//go:build !linux
+
+package healthcheck
+
+import "context"
+
+// ProcessMemoryCheck returns a Check that fails if the resident set size of
+// the process exceeds maxBytes. It is only supported on Linux.
+func ProcessMemoryCheck(maxBytes uint64) func(ctx context.Context) error {
+	return func(ctx context.Context) error {
+		return errUnsupportedPlatform
+	}
+}
+
+// ThreadCountCheck returns a Check that fails if the process runs more than
+// threshold OS threads. It is only supported on Linux.
+func ThreadCountCheck(threshold int) func(ctx context.Context) error {
+	return func(ctx context.Context) error {
+		return errUnsupportedPlatform
+	}
+}
+
+// FileDescriptorCheck returns a Check that fails if the process uses more
+// than thresholdPercent of the file descriptors allowed by its soft limit.
+// It is only supported on Linux.
+func FileDescriptorCheck(thresholdPercent float64) func(ctx context.Context) error {
+	return func(ctx context.Context) error {
+		return errUnsupportedPlatform
+	}
+}
+
+// ProcessCheck returns a Check that fails unless a process with the given
+// name is running. It is only supported on Linux.
+func ProcessCheck(name string, rejectZombie bool) func(ctx context.Context) error {
+	return func(ctx context.Context) error {
+		return errUnsupportedPlatform
+	}
+}
+
+// PIDFileCheck returns a Check that fails unless the process whose PID is
+// stored in the file at path is running. It is only supported on Linux.
+func PIDFileCheck(path string, rejectZombie bool) func(ctx context.Context) error {
+	return func(ctx context.Context) error {
+		return errUnsupportedPlatform
+	}
+}
@@ -0,0 +1,217 @@
+package healthcheck
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// PrometheusScrapeOptions configures PrometheusScrapeCheck.
+type PrometheusScrapeOptions struct {
+	// Metric is the name of a sample that must be exposed, e.g. "up" or
+	// "http_requests_total". Histograms and summaries are matched by the
+	// names of their series, e.g. "request_duration_seconds_count".
+	Metric string
+	// Labels restricts the samples of Metric to those with these label
+	// values.
+	Labels map[string]string
+	// Min and Max bound the values of all matching samples if not nil.
+	Min, Max *float64
+	// Timeout for the whole check. If zero, the deadline of the check
+	// context applies.
+	Timeout time.Duration
+}
+
+// PrometheusScrapeCheck returns a Check that scrapes the metrics endpoint at
+// url (e.g., "http://localhost:9100/metrics") and fails unless the response
+// is valid Prometheus text exposition format. If options.Metric is set, the
+// check also fails unless a matching sample exists and all matching samples
+// are within the bounds of options.
+func PrometheusScrapeCheck(url string, options PrometheusScrapeOptions) func(ctx context.Context) error {
+	client := &http.Client{Timeout: options.Timeout}
+	return func(ctx context.Context) error {
+		ctx, cancel := withOptionalTimeout(ctx, options.Timeout)
+		defer cancel()
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+		if err != nil {
+			return err
+		}
+		req.Header.Set("Accept", "text/plain;version=0.0.4")
+		resp, err := client.Do(req)
+		if err != nil {
+			return err
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode != 200 {
+			return fmt.Errorf("returned status %d", resp.StatusCode)
+		}
+		matches := 0
+		scanner := bufio.NewScanner(io.LimitReader(resp.Body, 64<<20))
+		scanner.Buffer(nil, 1<<20)
+		for line := 1; scanner.Scan(); line++ {
+			text := strings.TrimSpace(scanner.Text())
+			if text == "" || text[0] == '#' {
+				continue
+			}
+			sample, err := parsePrometheusSample(text)
+			if err != nil {
+				return fmt.Errorf("line %d: %w", line, err)
+			}
+			if options.Metric == "" || sample.name != options.Metric || !sample.hasLabels(options.Labels) {
+				continue
+			}
+			matches++
+			if options.Min != nil && !(sample.value >= *options.Min) || options.Max != nil && !(sample.value <= *options.Max) {
+				return fmt.Errorf("%s is %g, out of bounds", sample, sample.value)
+			}
+		}
+		if err := scanner.Err(); err != nil {
+			return err
+		}
+		if options.Metric != "" && matches == 0 {
+			return fmt.Errorf("metric %s not found", options.Metric)
+		}
+		return nil
+	}
+}
+
+type prometheusSample struct {
+	name   string
+	labels map[string]string
+	value  float64
+}
+
+// String returns the series of the sample, e.g. up{job="node"}.
+func (s *prometheusSample) String() string {
+	names := make([]string, 0, len(s.labels))
+	for name := range s.labels {
+		names = append(names, name)
+	}
+	if len(names) == 0 {
+		return s.name
+	}
+	sort.Strings(names)
+	for i, name := range names {
+		names[i] = name + "=" + strconv.Quote(s.labels[name])
+	}
+	return s.name + "{" + strings.Join(names, ",") + "}"
+}
+
+func (s *prometheusSample) hasLabels(labels map[string]string) bool {
+	for name, value := range labels {
+		if s.labels[name] != value {
+			return false
+		}
+	}
+	return true
+}
+
+// parsePrometheusSample parses a sample line of the text exposition format:
+//
+//	name{label="value",...} value [timestamp]
+func parsePrometheusSample(line string) (*prometheusSample, error) {
+	end := strings.IndexAny(line, "{ \t")
+	if end < 0 {
+		return nil, errors.New("missing value")
+	}
+	sample := &prometheusSample{name: line[:end], labels: map[string]string{}}
+	if !validPrometheusName(sample.name, true) {
+		return nil, fmt.Errorf("invalid metric name %q", sample.name)
+	}
+	rest := line[end:]
+	if rest[0] == '{' {
+		rest = rest[1:]
+		for {
+			rest = strings.TrimLeft(rest, " \t")
+			if strings.HasPrefix(rest, "}") {
+				rest = rest[1:]
+				break
+			}
+			equals := strings.IndexByte(rest, '=')
+			if equals < 0 {
+				return nil, errors.New("invalid labels")
+			}
+			name := strings.TrimSpace(rest[:equals])
+			if !validPrometheusName(name, false) {
+				return nil, fmt.Errorf("invalid label name %q", name)
+			}
+			value, remaining, err := parsePrometheusLabelValue(strings.TrimLeft(rest[equals+1:], " \t"))
+			if err != nil {
+				return nil, err
+			}
+			sample.labels[name] = value
+			rest = strings.TrimLeft(remaining, " \t")
+			if strings.HasPrefix(rest, ",") {
+				rest = rest[1:]
+			} else if !strings.HasPrefix(rest, "}") {
+				return nil, errors.New("invalid labels")
+			}
+		}
+	}
+	fields := strings.Fields(rest)
+	if len(fields) < 1 || len(fields) > 2 {
+		return nil, errors.New("invalid value")
+	}
+	value, err := strconv.ParseFloat(fields[0], 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid value %q", fields[0])
+	}
+	sample.value = value
+	if len(fields) == 2 {
+		if _, err := strconv.ParseInt(fields[1], 10, 64); err != nil {
+			return nil, fmt.Errorf("invalid timestamp %q", fields[1])
+		}
+	}
+	return sample, nil
+}
+
+// parsePrometheusLabelValue parses a quoted label value and returns it with
+// the remainder of s.
+func parsePrometheusLabelValue(s string) (string, string, error) {
+	if !strings.HasPrefix(s, `"`) {
+		return "", "", errors.New("unquoted label value")
+	}
+	var b strings.Builder
+	for i := 1; i < len(s); i++ {
+		switch c := s[i]; c {
+		case '"':
+			return b.String(), s[i+1:], nil
+		case '\\':
+			if i++; i == len(s) {
+				return "", "", errors.New("unterminated label value")
+			}
+			switch s[i] {
+			case 'n':
+				b.WriteByte('\n')
+			case '\\', '"':
+				b.WriteByte(s[i])
+			default:
+				return "", "", fmt.Errorf("invalid escape sequence \\%c", s[i])
+			}
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return "", "", errors.New("unterminated label value")
+}
+
+// validPrometheusName reports whether name is a valid metric name, which may
+// contain colons, or label name.
+func validPrometheusName(name string, metric bool) bool {
+	if name == "" {
+		return false
+	}
+	for i, c := range name {
+		if !(c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c == '_' || metric && c == ':' || i > 0 && c >= '0' && c <= '9') {
+			return false
+		}
+	}
+	return true
+}
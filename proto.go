@@ -0,0 +1,91 @@
+package healthcheck
+
+import (
+	"encoding/binary"
+	"fmt"
+)
+
+// The checks talking to gRPC services only need a handful of small messages,
+// which are encoded and decoded with the helpers below rather than with
+// generated code.
+
+const (
+	protoVarint = 0
+	protoBytes  = 2
+)
+
+// protoField is a decoded field of a protobuf message.
+type protoField struct {
+	number int
+	varint uint64
+	bytes  []byte
+}
+
+func appendUvarint(b []byte, v uint64) []byte {
+	var buf [binary.MaxVarintLen64]byte
+	n := binary.PutUvarint(buf[:], v)
+	return append(b, buf[:n]...)
+}
+
+func appendProtoTag(b []byte, number int, wireType int) []byte {
+	return appendUvarint(b, uint64(number)<<3|uint64(wireType))
+}
+
+func appendProtoVarint(b []byte, number int, v uint64) []byte {
+	b = appendProtoTag(b, number, protoVarint)
+	return appendUvarint(b, v)
+}
+
+func appendProtoBytes(b []byte, number int, v []byte) []byte {
+	b = appendProtoTag(b, number, protoBytes)
+	b = appendUvarint(b, uint64(len(v)))
+	return append(b, v...)
+}
+
+func appendProtoString(b []byte, number int, v string) []byte {
+	return appendProtoBytes(b, number, []byte(v))
+}
+
+// parseProto decodes the top level fields of a protobuf message. Fixed size
+// fields are skipped.
+func parseProto(b []byte) ([]protoField, error) {
+	var fields []protoField
+	for len(b) > 0 {
+		tag, n := binary.Uvarint(b)
+		if n <= 0 {
+			return nil, fmt.Errorf("invalid protobuf tag")
+		}
+		b = b[n:]
+		field := protoField{number: int(tag >> 3)}
+		switch tag & 7 {
+		case protoVarint:
+			v, n := binary.Uvarint(b)
+			if n <= 0 {
+				return nil, fmt.Errorf("invalid protobuf varint")
+			}
+			field.varint, b = v, b[n:]
+		case protoBytes:
+			l, n := binary.Uvarint(b)
+			if n <= 0 || uint64(len(b)-n) < l {
+				return nil, fmt.Errorf("invalid protobuf length")
+			}
+			field.bytes, b = b[n:n+int(l)], b[n+int(l):]
+		case 1: // 64-bit
+			if len(b) < 8 {
+				return nil, fmt.Errorf("truncated protobuf message")
+			}
+			b = b[8:]
+			continue
+		case 5: // 32-bit
+			if len(b) < 4 {
+				return nil, fmt.Errorf("truncated protobuf message")
+			}
+			b = b[4:]
+			continue
+		default:
+			return nil, fmt.Errorf("unsupported protobuf wire type %d", tag&7)
+		}
+		fields = append(fields, field)
+	}
+	return fields, nil
+}
@@ -0,0 +1,79 @@
+package healthcheck
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestParseProto(t *testing.T) {
+	tests := []struct {
+		name    string
+		message []byte
+		want    []protoField
+		wantErr bool
+	}{
+		{name: "empty", message: nil, want: nil},
+		{name: "varint", message: []byte{0x08, 0x96, 0x01}, want: []protoField{{number: 1, varint: 150}}},
+		{name: "string", message: []byte{0x12, 0x03, 'a', 'b', 'c'}, want: []protoField{{number: 2, bytes: []byte("abc")}}},
+		{name: "empty bytes", message: []byte{0x1a, 0x00}, want: []protoField{{number: 3, bytes: []byte{}}}},
+		{name: "large field number", message: []byte{0x80, 0x01, 0x01}, want: []protoField{{number: 16, varint: 1}}},
+		{
+			name:    "fixed size fields skipped",
+			message: []byte{0x09, 1, 2, 3, 4, 5, 6, 7, 8, 0x15, 1, 2, 3, 4, 0x18, 0x07},
+			want:    []protoField{{number: 3, varint: 7}},
+		},
+		{name: "truncated tag", message: []byte{0x80}, wantErr: true},
+		{name: "truncated varint", message: []byte{0x08, 0x96}, wantErr: true},
+		{name: "length beyond message", message: []byte{0x12, 0x05, 'a'}, wantErr: true},
+		{name: "truncated 64-bit", message: []byte{0x09, 1, 2}, wantErr: true},
+		{name: "truncated 32-bit", message: []byte{0x15, 1}, wantErr: true},
+		{name: "group wire type", message: []byte{0x0b}, wantErr: true},
+	}
+	for _, test := range tests {
+		fields, err := parseProto(test.message)
+		if test.wantErr {
+			if err == nil {
+				t.Errorf("%s: no error, got %v", test.name, fields)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: %v", test.name, err)
+			continue
+		}
+		if !equalProtoFields(fields, test.want) {
+			t.Errorf("%s: got %v, want %v", test.name, fields, test.want)
+		}
+	}
+}
+
+func TestAppendProtoRoundTrip(t *testing.T) {
+	var message []byte
+	message = appendProtoString(message, 1, "grpc.health.v1.Health")
+	message = appendProtoVarint(message, 2, 1<<40)
+	message = appendProtoBytes(message, 300, []byte{0, 1, 2})
+	fields, err := parseProto(message)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []protoField{
+		{number: 1, bytes: []byte("grpc.health.v1.Health")},
+		{number: 2, varint: 1 << 40},
+		{number: 300, bytes: []byte{0, 1, 2}},
+	}
+	if !equalProtoFields(fields, want) {
+		t.Errorf("got %v, want %v", fields, want)
+	}
+}
+
+func equalProtoFields(a, b []protoField) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i].number != b[i].number || a[i].varint != b[i].varint || !bytes.Equal(a[i].bytes, b[i].bytes) {
+			return false
+		}
+	}
+	return true
+}
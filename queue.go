@@ -0,0 +1,118 @@
+package healthcheck
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// QueueDepthCheck returns a Check that fails if the number of messages
+// waiting in a queue, as returned by depth, exceeds maxDepth. A consumer
+// falling behind can use it to stop accepting additional work. depth can
+// wrap any queue client, e.g. the length of a Redis list or the lag of a
+// Kafka consumer group.
+func QueueDepthCheck(depth func(ctx context.Context) (int64, error), maxDepth int64, timeout time.Duration) func(ctx context.Context) error {
+	return func(ctx context.Context) error {
+		ctx, cancel := withOptionalTimeout(ctx, timeout)
+		defer cancel()
+		n, err := depth(ctx)
+		if err != nil {
+			return err
+		}
+		if n > maxDepth {
+			return fmt.Errorf("queue depth %d > %d", n, maxDepth)
+		}
+		return nil
+	}
+}
+
+// SQSOptions configures the connection to an Amazon SQS queue.
+type SQSOptions struct {
+	// QueueURL is the URL of the queue, such as
+	// "https://sqs.eu-west-1.amazonaws.com/123456789012/jobs".
+	QueueURL string
+	// Region used to sign the requests. Default is the region of QueueURL,
+	// or "us-east-1" if it has none.
+	Region string
+	// Credentials used to sign the requests.
+	Credentials AWSCredentials
+	// Timeout for the whole check. If zero, the deadline of the check context
+	// applies.
+	Timeout time.Duration
+}
+
+// SQSQueueDepthCheck returns a QueueDepthCheck for the approximate number of
+// visible messages of the SQS queue.
+func SQSQueueDepthCheck(options SQSOptions, maxDepth int64) func(ctx context.Context) error {
+	client := &http.Client{}
+	return QueueDepthCheck(func(ctx context.Context) (int64, error) {
+		return sqsQueueDepth(ctx, client, options)
+	}, maxDepth, options.Timeout)
+}
+
+// sqsQueueDepth queries the ApproximateNumberOfMessages attribute of the
+// queue with the query API.
+func sqsQueueDepth(ctx context.Context, client *http.Client, options SQSOptions) (int64, error) {
+	region := options.Region
+	if region == "" {
+		region = "us-east-1"
+		if u, err := url.Parse(options.QueueURL); err == nil {
+			// Host names have the format "sqs.<region>.amazonaws.com".
+			if parts := strings.Split(u.Hostname(), "."); len(parts) > 2 && parts[0] == "sqs" {
+				region = parts[1]
+			}
+		}
+	}
+	body := []byte(url.Values{
+		"Action":          {"GetQueueAttributes"},
+		"AttributeName.1": {"ApproximateNumberOfMessages"},
+		"Version":         {"2012-11-05"},
+	}.Encode())
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, options.QueueURL, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	signAWSRequest(req, body, region, "sqs", options.Credentials)
+	resp, err := client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	content, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return 0, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		var errorResponse struct {
+			Code    string `xml:"Error>Code"`
+			Message string `xml:"Error>Message"`
+		}
+		if xml.Unmarshal(content, &errorResponse) == nil && errorResponse.Code != "" {
+			return 0, fmt.Errorf("returned status %d: %s: %s", resp.StatusCode, errorResponse.Code, errorResponse.Message)
+		}
+		return 0, fmt.Errorf("returned status %d", resp.StatusCode)
+	}
+	var response struct {
+		Attributes []struct {
+			Name  string `xml:"Name"`
+			Value string `xml:"Value"`
+		} `xml:"GetQueueAttributesResult>Attribute"`
+	}
+	if err := xml.Unmarshal(content, &response); err != nil {
+		return 0, fmt.Errorf("invalid response: %w", err)
+	}
+	for _, attribute := range response.Attributes {
+		if attribute.Name == "ApproximateNumberOfMessages" {
+			return strconv.ParseInt(attribute.Value, 10, 64)
+		}
+	}
+	return 0, fmt.Errorf("queue depth not returned")
+}
@@ -0,0 +1,168 @@
+package healthcheck
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"time"
+)
+
+// RedisOptions configures a RedisPingCheck.
+type RedisOptions struct {
+	// Addr is the host:port of the Redis server.
+	Addr string
+	// Username and Password authenticate the connection if Password is set.
+	// Username may be empty for servers without ACLs.
+	Username string
+	Password string
+	// DB is the database selected after connecting.
+	DB int
+	// TLS enables TLS with the given configuration if not nil.
+	TLS *tls.Config
+	// ProbeKey, if set, is written, read back and deleted on every check to
+	// verify that the server accepts writes.
+	ProbeKey string
+	// Timeout for the whole check. If zero, the deadline of the check
+	// context applies.
+	Timeout time.Duration
+}
+
+// RedisPingCheck returns a Check that connects to a Redis server and sends a
+// PING, and writes and reads back the probe key if one is configured.
+func RedisPingCheck(options RedisOptions) func(ctx context.Context) error {
+	return func(ctx context.Context) error {
+		ctx, cancel := withOptionalTimeout(ctx, options.Timeout)
+		defer cancel()
+		conn, err := dialRedis(ctx, options)
+		if err != nil {
+			return err
+		}
+		defer conn.Close()
+		reply, err := conn.do("PING")
+		if err != nil {
+			return err
+		}
+		if reply != "PONG" {
+			return fmt.Errorf("unexpected PING reply %v", reply)
+		}
+		if options.ProbeKey == "" {
+			return nil
+		}
+		value := strconv.FormatInt(time.Now().UnixNano(), 36)
+		ttl := strconv.FormatInt(int64(options.Timeout/time.Millisecond)+1000, 10)
+		if _, err := conn.do("SET", options.ProbeKey, value, "PX", ttl); err != nil {
+			return fmt.Errorf("writing probe key: %w", err)
+		}
+		reply, err = conn.do("GET", options.ProbeKey)
+		if err != nil {
+			return fmt.Errorf("reading probe key: %w", err)
+		}
+		if reply != value {
+			return fmt.Errorf("probe key has value %v, expected %s", reply, value)
+		}
+		if _, err := conn.do("DEL", options.ProbeKey); err != nil {
+			return fmt.Errorf("deleting probe key: %w", err)
+		}
+		return nil
+	}
+}
+
+// redisConn is a minimal client of the Redis serialization protocol (RESP).
+type redisConn struct {
+	net.Conn
+	reader *bufio.Reader
+}
+
+// dialRedis connects to the server and authenticates and selects the
+// database as configured by options.
+func dialRedis(ctx context.Context, options RedisOptions) (*redisConn, error) {
+	conn, err := dialTCP(ctx, options.Addr, options.TLS)
+	if err != nil {
+		return nil, err
+	}
+	rc := &redisConn{Conn: conn, reader: bufio.NewReader(conn)}
+	if options.Password != "" {
+		args := []string{"AUTH", options.Password}
+		if options.Username != "" {
+			args = []string{"AUTH", options.Username, options.Password}
+		}
+		if _, err := rc.do(args...); err != nil {
+			conn.Close()
+			return nil, fmt.Errorf("authentication failed: %w", err)
+		}
+	}
+	if options.DB != 0 {
+		if _, err := rc.do("SELECT", strconv.Itoa(options.DB)); err != nil {
+			conn.Close()
+			return nil, err
+		}
+	}
+	return rc, nil
+}
+
+// do sends a command and returns its reply. Error replies are returned as
+// error; a nil bulk string is returned as nil.
+func (rc *redisConn) do(args ...string) (interface{}, error) {
+	command := "*" + strconv.Itoa(len(args)) + "\r\n"
+	for _, arg := range args {
+		command += "$" + strconv.Itoa(len(arg)) + "\r\n" + arg + "\r\n"
+	}
+	if _, err := io.WriteString(rc.Conn, command); err != nil {
+		return nil, err
+	}
+	return rc.readReply()
+}
+
+func (rc *redisConn) readReply() (interface{}, error) {
+	line, err := rc.reader.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+	if len(line) < 3 || line[len(line)-2] != '\r' {
+		return nil, fmt.Errorf("malformed reply %q", line)
+	}
+	kind, payload := line[0], line[1:len(line)-2]
+	switch kind {
+	case '+':
+		return payload, nil
+	case '-':
+		return nil, errors.New(payload)
+	case ':':
+		return strconv.ParseInt(payload, 10, 64)
+	case '$':
+		n, err := strconv.Atoi(payload)
+		if err != nil || n < 0 {
+			return nil, err
+		}
+		if n > 1<<20 {
+			return nil, fmt.Errorf("bulk string too large: %d bytes", n)
+		}
+		data := make([]byte, n+2)
+		if _, err := io.ReadFull(rc.reader, data); err != nil {
+			return nil, err
+		}
+		return string(data[:n]), nil
+	case '*':
+		n, err := strconv.Atoi(payload)
+		if err != nil || n < 0 {
+			return nil, err
+		}
+		if n > 1<<20 {
+			return nil, fmt.Errorf("array too large: %d elements", n)
+		}
+		elements := make([]interface{}, n)
+		for i := range elements {
+			if elements[i], err = rc.readReply(); err != nil {
+				return nil, err
+			}
+		}
+		return elements, nil
+	default:
+		return nil, fmt.Errorf("unsupported reply type %q", kind)
+	}
+}
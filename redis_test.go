@@ -0,0 +1,63 @@
+package healthcheck
+
+import (
+	"bufio"
+	"context"
+	"net"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRedisPingCheckWithoutTimeout(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		reader := bufio.NewReader(conn)
+		for {
+			line, err := reader.ReadString('\n')
+			if err != nil {
+				return
+			}
+			if strings.EqualFold(strings.TrimSpace(line), "PING") {
+				conn.Write([]byte("+PONG\r\n"))
+			}
+		}
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err := RedisPingCheck(RedisOptions{Addr: listener.Addr().String()})(ctx); err != nil {
+		t.Errorf("RedisPingCheck with zero timeout: %v", err)
+	}
+}
+
+func TestRedisReadReply(t *testing.T) {
+	tests := []struct {
+		input string
+		ok    bool
+	}{
+		{"+PONG\r\n", true},
+		{"$5\r\nhello\r\n", true},
+		{"$-1\r\n", true},
+		{"*2\r\n:1\r\n$1\r\na\r\n", true},
+		{"$9223372036854775807\r\n", false},
+		{"$2097152\r\n", false},
+		{"*9223372036854775807\r\n", false},
+		{"-ERR unknown command\r\n", false},
+	}
+	for _, tt := range tests {
+		rc := &redisConn{reader: bufio.NewReader(strings.NewReader(tt.input))}
+		if _, err := rc.readReply(); (err == nil) != tt.ok {
+			t.Errorf("readReply(%q) = %v", tt.input, err)
+		}
+	}
+}
@@ -0,0 +1,67 @@
+package healthcheck
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+)
+
+// checkerResponse is the JSON body written by resultWriter. It extends
+// CheckerResult with the information tracked by this package.
+type checkerResponse struct {
+	Status AvailabilityStatus `json:"status"`
+	// Override is the status forced by SetOverride, if any.
+	Override AvailabilityStatus       `json:"override,omitempty"`
+	Trend    Trend                    `json:"trend,omitempty"`
+	Details  map[string]checkResponse `json:"details,omitempty"`
+}
+
+type checkResponse struct {
+	CheckResult
+	Trend  Trend   `json:"trend,omitempty"`
+	Causes []Cause `json:"causes,omitempty"`
+}
+
+// resultWriter writes the response of the checker handler, a checkerResponse.
+type resultWriter struct {
+	trends   *trendTracker
+	causes   *causeRecorder
+	override *overrideState
+}
+
+// Write writes result as the response to r with the given status code.
+func (rw *resultWriter) Write(result *CheckerResult, statusCode int, w http.ResponseWriter, r *http.Request) error {
+	jsonResp, err := json.Marshal(rw.response(result))
+	if err != nil {
+		return fmt.Errorf("cannot marshal response: %w", err)
+	}
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(statusCode)
+	_, err = w.Write(jsonResp)
+	return err
+}
+
+func (rw *resultWriter) response(result *CheckerResult) checkerResponse {
+	resp := checkerResponse{Status: result.Status}
+	if rw.override != nil {
+		resp.Override = rw.override.get()
+	}
+	if rw.trends != nil {
+		resp.Trend = rw.trends.trend(overallTrendKey)
+	}
+	if result.Details == nil {
+		return resp
+	}
+	resp.Details = map[string]checkResponse{}
+	for name, check := range result.Details {
+		details := checkResponse{CheckResult: check}
+		if rw.trends != nil {
+			details.Trend = rw.trends.trend(name)
+		}
+		if rw.causes != nil && check.Status != StatusUp {
+			details.Causes = rw.causes.get(name)
+		}
+		resp.Details[name] = details
+	}
+	return resp
+}
@@ -0,0 +1,104 @@
+package healthcheck
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+)
+
+// ScoreResult is the body returned by the score handler (see
+// GetScoreHandler).
+type ScoreResult struct {
+	// Score is the health of the system between 0 (all checks down) and 100
+	// (all checks up). Degraded checks contribute half of their weight.
+	Score float64 `json:"score"`
+	// Status is the aggregated system availability status.
+	Status AvailabilityStatus `json:"status"`
+	// Components holds the contribution of every check to the score.
+	Components map[string]ScoreComponent `json:"components,omitempty"`
+}
+
+// ScoreComponent describes how much a single check contributes to the score.
+type ScoreComponent struct {
+	Status       AvailabilityStatus `json:"status"`
+	Weight       float64            `json:"weight"`
+	Contribution float64            `json:"contribution"`
+}
+
+// SetCheckWeight sets the weight of the named check in the health score (see
+// GetScoreHandler). Checks without an explicit weight have a weight of 1.
+func (c *AndictlCheckerConfig) SetCheckWeight(name string, weight float64) {
+	if c.weights == nil {
+		c.weights = map[string]float64{}
+	}
+	c.weights[name] = weight
+}
+
+// GetScoreHandler returns a handler that reports a numeric health score
+// instead of a binary status, to be used for weighted traffic steering. By
+// default the score is written as JSON (see ScoreResult). With the query
+// parameter format=agent the response is a HAProxy agent-check compatible
+// percentage such as "75%". The handler always answers with status code 200.
+func (c AndictlCheckerConfig) GetScoreHandler() http.HandlerFunc {
+	checker := c.newChecker()
+	return func(w http.ResponseWriter, r *http.Request) {
+		score := c.score(checker.Check(r.Context()))
+		w.Header().Set("Cache-Control", "no-cache")
+		w.Header().Set("Pragma", "no-cache")
+		w.Header().Set("Expires", "-1")
+		if r.URL.Query().Get("format") == "agent" {
+			w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+			fmt.Fprintf(w, "%d%%\n", int(math.Round(score.Score)))
+			return
+		}
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+		json.NewEncoder(w).Encode(score)
+	}
+}
+
+func (c AndictlCheckerConfig) weight(name string) float64 {
+	if weight, ok := c.weights[name]; ok {
+		return weight
+	}
+	return 1
+}
+
+func (c AndictlCheckerConfig) score(result CheckerResult) ScoreResult {
+	score := ScoreResult{Status: result.Status}
+	// Checks in maintenance or skipped do not count against the score.
+	var total float64
+	counted := 0
+	for name, check := range result.Details {
+		if check.Status != StatusMaintenance && check.Status != StatusSkipped {
+			total += c.weight(name)
+			counted++
+		}
+	}
+	if counted == 0 {
+		switch result.Status {
+		case StatusUp, StatusMaintenance:
+			score.Score = 100
+		case StatusDegraded:
+			score.Score = 50
+		}
+		if len(result.Details) == 0 {
+			return score
+		}
+	}
+	score.Components = map[string]ScoreComponent{}
+	for name, check := range result.Details {
+		component := ScoreComponent{Status: check.Status, Weight: c.weight(name)}
+		if total > 0 {
+			switch check.Status {
+			case StatusUp:
+				component.Contribution = component.Weight / total * 100
+			case StatusDegraded:
+				component.Contribution = component.Weight / total * 50
+			}
+		}
+		score.Score += component.Contribution
+		score.Components[name] = component
+	}
+	return score
+}
@@ -0,0 +1,41 @@
+package healthcheck
+
+import "context"
+
+// Severity decides how the failure of a check affects the overall status.
+type Severity int
+
+const (
+	// SeverityCritical checks take the system down when they fail (status
+	// code 503).
+	SeverityCritical Severity = iota
+	// SeverityWarning checks are reported as degraded when they fail, so the
+	// system stays available (status code 200) with the overall status
+	// degraded. It suits optional dependencies such as a metrics backend.
+	SeverityWarning
+)
+
+// SetCheckSeverity sets the severity of the named check. Checks without an
+// explicit severity have SeverityCritical. The severity is independent of
+// the critical-first strategy (see AddCriticalCheck), which only affects the
+// order of evaluation.
+func (c *AndictlCheckerConfig) SetCheckSeverity(name string, severity Severity) {
+	if c.severities == nil {
+		c.severities = map[string]Severity{}
+	}
+	c.severities[name] = severity
+}
+
+// severityInterceptor returns an interceptor that reports failing warning
+// checks as degraded rather than down.
+func severityInterceptor(severities map[string]Severity) interceptor {
+	return func(next interceptorFunc) interceptorFunc {
+		return func(ctx context.Context, name string, state checkState) checkState {
+			state = next(ctx, name, state)
+			if state.Status == StatusDown && severities[name] == SeverityWarning {
+				state.Status = StatusDegraded
+			}
+			return state
+		}
+	}
+}
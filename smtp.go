@@ -0,0 +1,128 @@
+package healthcheck
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/smtp"
+	"time"
+)
+
+// SMTPCheck returns a Check that connects to the SMTP server at addr,
+// completes EHLO and quits. If config is not nil, the connection is upgraded
+// with STARTTLS and the check fails if the server does not support it. If
+// auth is not nil, the client also authenticates.
+func SMTPCheck(addr string, config *tls.Config, auth smtp.Auth, timeout time.Duration) func(ctx context.Context) error {
+	return func(ctx context.Context) error {
+		ctx, cancel := withOptionalTimeout(ctx, timeout)
+		defer cancel()
+		client, err := dialSMTP(ctx, addr, config, auth)
+		if err != nil {
+			return err
+		}
+		defer client.Close()
+		return client.Quit()
+	}
+}
+
+// SMTPCanaryOptions configures an SMTPCanaryCheck.
+type SMTPCanaryOptions struct {
+	// Addr is the host:port of the mail relay.
+	Addr string
+	// From is the envelope sender of the canary message.
+	From string
+	// To is the sink mailbox receiving the canary message.
+	To string
+	// Auth authenticates against the relay if not nil.
+	Auth smtp.Auth
+	// TLS enables STARTTLS with the given configuration if not nil. The
+	// check fails if the relay does not support STARTTLS.
+	TLS *tls.Config
+	// Timeout for the whole transaction. If zero, the deadline of the check
+	// context applies.
+	Timeout time.Duration
+}
+
+// SMTPCanaryCheck returns a Check that sends a small message to a sink
+// mailbox through the relay and fails unless the relay accepts it.
+func SMTPCanaryCheck(options SMTPCanaryOptions) func(ctx context.Context) error {
+	return func(ctx context.Context) error {
+		ctx, cancel := withOptionalTimeout(ctx, options.Timeout)
+		defer cancel()
+		client, err := dialSMTP(ctx, options.Addr, options.TLS, options.Auth)
+		if err != nil {
+			return err
+		}
+		defer client.Close()
+		if err := client.Mail(options.From); err != nil {
+			return err
+		}
+		if err := client.Rcpt(options.To); err != nil {
+			return err
+		}
+		w, err := client.Data()
+		if err != nil {
+			return err
+		}
+		now := time.Now()
+		_, err = fmt.Fprintf(w, "From: %s\r\nTo: %s\r\nSubject: healthcheck canary\r\nDate: %s\r\n"+
+			"Message-ID: <%d.healthcheck@localhost>\r\n\r\nThis message was sent by a health check.\r\n",
+			options.From, options.To, now.Format(time.RFC1123Z), now.UnixNano())
+		if err != nil {
+			return err
+		}
+		// Close fails unless the relay accepted the message.
+		if err := w.Close(); err != nil {
+			return err
+		}
+		return client.Quit()
+	}
+}
+
+// dialSMTP connects to the SMTP server at addr, greets it and, if requested,
+// upgrades the connection with STARTTLS and authenticates.
+func dialSMTP(ctx context.Context, addr string, config *tls.Config, auth smtp.Auth) (*smtp.Client, error) {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return nil, err
+	}
+	dialer := net.Dialer{}
+	conn, err := dialer.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+	client, err := smtp.NewClient(conn, host)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	if err := client.Hello("localhost"); err != nil {
+		client.Close()
+		return nil, err
+	}
+	if config != nil {
+		if ok, _ := client.Extension("STARTTLS"); !ok {
+			client.Close()
+			return nil, fmt.Errorf("server does not support STARTTLS")
+		}
+		config = config.Clone()
+		if config.ServerName == "" {
+			config.ServerName = host
+		}
+		if err := client.StartTLS(config); err != nil {
+			client.Close()
+			return nil, err
+		}
+	}
+	if auth != nil {
+		if err := client.Auth(auth); err != nil {
+			client.Close()
+			return nil, err
+		}
+	}
+	return client, nil
+}
@@ -0,0 +1,117 @@
+package healthcheck
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Snapshot is the health of the system at one point in time.
+type Snapshot struct {
+	// Status is the aggregated system availability status.
+	Status AvailabilityStatus
+	// Checks holds the results of the individual checks by check name.
+	Checks map[string]CheckSnapshot
+	// CheckedAt is the time of the evaluation.
+	CheckedAt time.Time
+}
+
+// CheckSnapshot is the result of a single check in a Snapshot.
+type CheckSnapshot struct {
+	Status AvailabilityStatus
+	// Error is the error message of the check, if it failed.
+	Error string
+	// CheckedAt is the time when the check was executed, zero if it was not
+	// executed yet.
+	CheckedAt time.Time
+}
+
+// Available reports whether the system is considered available, that is
+// neither down nor unknown. Degraded systems are available.
+func (s Snapshot) Available() bool {
+	return s.Status != StatusDown && s.Status != StatusUnknown
+}
+
+func newSnapshot(result CheckerResult, checkedAt time.Time) Snapshot {
+	snapshot := Snapshot{
+		Status:    result.Status,
+		Checks:    make(map[string]CheckSnapshot, len(result.Details)),
+		CheckedAt: checkedAt,
+	}
+	for name, check := range result.Details {
+		details := CheckSnapshot{Status: check.Status}
+		if check.Error != nil {
+			details.Error = *check.Error
+		}
+		if check.Timestamp != nil {
+			details.CheckedAt = *check.Timestamp
+		}
+		snapshot.Checks[name] = details
+	}
+	return snapshot
+}
+
+// snapshotRecorder keeps the most recent snapshot of all checkers created by
+// a configuration, and the checker used by Status.
+type snapshotRecorder struct {
+	mtx     sync.Mutex
+	last    Snapshot
+	once    sync.Once
+	checker engine
+}
+
+func newSnapshotRecorder() *snapshotRecorder {
+	return &snapshotRecorder{last: Snapshot{Status: StatusUnknown}}
+}
+
+func (sr *snapshotRecorder) record(snapshot Snapshot) {
+	sr.mtx.Lock()
+	defer sr.mtx.Unlock()
+	sr.last = snapshot
+}
+
+func (sr *snapshotRecorder) lastSnapshot() Snapshot {
+	sr.mtx.Lock()
+	defer sr.mtx.Unlock()
+	return sr.last
+}
+
+// snapshotChecker records every result of the wrapped checker.
+type snapshotChecker struct {
+	engine
+	recorder *snapshotRecorder
+}
+
+func (ck *snapshotChecker) Check(ctx context.Context) CheckerResult {
+	result := ck.engine.Check(ctx)
+	ck.recorder.record(newSnapshot(result, time.Now()))
+	return result
+}
+
+// Status evaluates the checks and returns the current health of the system,
+// without going through HTTP. Application code can use it to gate work on
+// health, e.g., to pause consumers while a dependency is down. The checker
+// used by Status is built on the first call, with the checks registered by
+// then. Results are cached like those of the checker handler.
+func (c *AndictlCheckerConfig) Status(ctx context.Context) Snapshot {
+	recorder := c.snapshotRecorder()
+	recorder.once.Do(func() {
+		recorder.checker = c.newChecker()
+	})
+	return newSnapshot(recorder.checker.Check(ctx), time.Now())
+}
+
+// LastStatus returns the most recent health of the system as evaluated by
+// Status or by any handler of this configuration other than the probe
+// handlers (see GetReadinessHandler), without executing any check. Before
+// the first evaluation the status is unknown.
+func (c *AndictlCheckerConfig) LastStatus() Snapshot {
+	return c.snapshotRecorder().lastSnapshot()
+}
+
+func (c *AndictlCheckerConfig) snapshotRecorder() *snapshotRecorder {
+	if c.snapshots == nil {
+		c.snapshots = newSnapshotRecorder()
+	}
+	return c.snapshots
+}
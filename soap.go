@@ -0,0 +1,155 @@
+package healthcheck
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// SOAPCheckOptions configures a SOAPCheck.
+type SOAPCheckOptions struct {
+	// URL is the endpoint the envelope is posted to.
+	URL string
+	// Action is sent as SOAPAction header if not empty.
+	Action string
+	// Envelope is the request body.
+	Envelope string
+	// ContentType defaults to "text/xml; charset=utf-8" (SOAP 1.1). Use
+	// "application/soap+xml; charset=utf-8" for SOAP 1.2.
+	ContentType string
+	// Headers holds additional request headers.
+	Headers map[string]string
+	// Path selects the element of the response that must be present. It is a
+	// subset of XPath: element local names separated by slashes, absolute
+	// from the document root ("/Envelope/Body/PingResponse/Status") or
+	// relative to any element ("//Status"). Namespaces are ignored. An empty
+	// Path only checks for the absence of a SOAP fault.
+	Path string
+	// Expected is the text the selected element must contain. An empty
+	// Expected only requires the element to be present.
+	Expected string
+	// Timeout for the whole request.
+	Timeout time.Duration
+}
+
+// SOAPCheck returns a Check that posts a SOAP envelope and fails if the
+// request times out, the response contains a SOAP fault, or the element
+// selected by Path is missing or does not hold the expected text.
+func SOAPCheck(options SOAPCheckOptions) func(ctx context.Context) error {
+	client := http.Client{Timeout: options.Timeout}
+	contentType := options.ContentType
+	if contentType == "" {
+		contentType = "text/xml; charset=utf-8"
+	}
+	descendant := strings.HasPrefix(options.Path, "//")
+	var path []string
+	if p := strings.Trim(options.Path, "/"); p != "" {
+		path = strings.Split(p, "/")
+	}
+	return func(ctx context.Context) error {
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, options.URL, strings.NewReader(options.Envelope))
+		if err != nil {
+			return err
+		}
+		req.Header.Set("Content-Type", contentType)
+		if options.Action != "" {
+			req.Header.Set("SOAPAction", options.Action)
+		}
+		for key, value := range options.Headers {
+			req.Header.Set(key, value)
+		}
+		resp, err := client.Do(req)
+		if err != nil {
+			return err
+		}
+		defer resp.Body.Close()
+		found, text, err := scanSOAPResponse(resp.Body, path, descendant)
+		if err != nil {
+			return err
+		}
+		// SOAP faults are returned with status 500 and are reported above.
+		if resp.StatusCode != 200 {
+			return fmt.Errorf("returned status %d", resp.StatusCode)
+		}
+		if len(path) == 0 {
+			return nil
+		}
+		if !found {
+			return fmt.Errorf("element %s not found in response", options.Path)
+		}
+		if options.Expected != "" && text != options.Expected {
+			return fmt.Errorf("element %s is %q, expected %q", options.Path, text, options.Expected)
+		}
+		return nil
+	}
+}
+
+// scanSOAPResponse looks for a SOAP fault and the element selected by path in
+// the XML document r. It returns whether the element was found and its text.
+func scanSOAPResponse(r io.Reader, path []string, descendant bool) (bool, string, error) {
+	decoder := xml.NewDecoder(r)
+	var (
+		stack     []string
+		found     bool
+		capturing bool
+		text      strings.Builder
+		fault     bool
+		faultText strings.Builder
+	)
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return false, "", fmt.Errorf("invalid XML response: %w", err)
+		}
+		switch t := token.(type) {
+		case xml.StartElement:
+			stack = append(stack, t.Name.Local)
+			if t.Name.Local == "Fault" && len(stack) >= 2 && stack[len(stack)-2] == "Body" {
+				fault = true
+			}
+			if !found && len(path) > 0 && matchXMLPath(stack, path, descendant) {
+				found, capturing = true, true
+			}
+		case xml.EndElement:
+			if capturing && matchXMLPath(stack, path, descendant) {
+				capturing = false
+			}
+			stack = stack[:len(stack)-1]
+		case xml.CharData:
+			if capturing {
+				text.Write(t)
+			}
+			if fault && len(stack) > 0 && (stack[len(stack)-1] == "faultstring" || stack[len(stack)-1] == "Text") {
+				faultText.Write(t)
+			}
+		}
+	}
+	if fault {
+		return false, "", fmt.Errorf("SOAP fault: %s", strings.TrimSpace(faultText.String()))
+	}
+	return found, strings.TrimSpace(text.String()), nil
+}
+
+func matchXMLPath(stack, path []string, descendant bool) bool {
+	if descendant {
+		if len(stack) < len(path) {
+			return false
+		}
+		stack = stack[len(stack)-len(path):]
+	} else if len(stack) != len(path) {
+		return false
+	}
+	for i := range path {
+		if stack[i] != path[i] {
+			return false
+		}
+	}
+	return true
+}
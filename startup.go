@@ -0,0 +1,33 @@
+package healthcheck
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// WithStartupGracePeriod reports failing checks as up during the first
+// period after InitChecker, so that services with a slow warmup, e.g. of
+// their caches, are not restarted before they are ready. The error of a
+// check failing during the grace period is still reported, prefixed with
+// "starting".
+func WithStartupGracePeriod(period time.Duration) Option {
+	return func(cfg *engineConfig) {
+		cfg.startupGracePeriod = period
+	}
+}
+
+// startupGraceInterceptor returns an interceptor that reports checks failing
+// before end as up.
+func startupGraceInterceptor(end time.Time) interceptor {
+	return func(next interceptorFunc) interceptorFunc {
+		return func(ctx context.Context, name string, state checkState) checkState {
+			state = next(ctx, name, state)
+			if state.Status == StatusDown && time.Now().Before(end) {
+				state.Result = fmt.Errorf("starting: %w", state.Result)
+				state.Status = StatusUp
+			}
+			return state
+		}
+	}
+}
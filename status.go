@@ -0,0 +1,50 @@
+package healthcheck
+
+// AvailabilityStatus expresses the availability of a component or of the
+// whole system.
+type AvailabilityStatus string
+
+const (
+	// StatusUnknown holds the information that the availability status is not
+	// known, because not all checks were executed yet.
+	StatusUnknown AvailabilityStatus = "unknown"
+	// StatusUp holds the information that a component or the system is up and
+	// running.
+	StatusUp AvailabilityStatus = "up"
+	// StatusDown holds the information that a component or the system is down
+	// and not available.
+	StatusDown AvailabilityStatus = "down"
+	// StatusDegraded holds the information that a component or the system is
+	// available but does not perform as expected. Degraded is reported with
+	// the status code of an available system.
+	StatusDegraded AvailabilityStatus = "degraded"
+	// StatusMaintenance holds the information that a component or the system
+	// is in planned maintenance (see StartMaintenance). It does not count
+	// against the availability of the system.
+	StatusMaintenance AvailabilityStatus = "maintenance"
+	// StatusSkipped holds the information that a check was not executed
+	// because a check it depends on failed (see SetCheckDependencies). It
+	// does not count against the availability of the system, which already
+	// reflects the failed dependency.
+	StatusSkipped AvailabilityStatus = "skipped"
+)
+
+func worstStatus(a, b AvailabilityStatus) AvailabilityStatus {
+	if criticality(b) > criticality(a) {
+		return b
+	}
+	return a
+}
+
+func criticality(status AvailabilityStatus) int {
+	switch status {
+	case StatusDown:
+		return 3
+	case StatusUnknown:
+		return 2
+	case StatusDegraded:
+		return 1
+	default:
+		return 0
+	}
+}
@@ -0,0 +1,46 @@
+package healthcheck
+
+import "context"
+
+// criticalFirstChecker is an engine that evaluates the critical checks
+// before all other checks. When skipOnDown is set and the critical checks
+// already report the system as down, the remaining checks are not executed.
+type criticalFirstChecker struct {
+	critical   engine
+	rest       engine
+	skipOnDown bool
+	aggregator Aggregator
+}
+
+func (ck *criticalFirstChecker) Start() {
+	ck.critical.Start()
+	ck.rest.Start()
+}
+
+func (ck *criticalFirstChecker) Stop() {
+	ck.critical.Stop()
+	ck.rest.Stop()
+}
+
+func (ck *criticalFirstChecker) Check(ctx context.Context) CheckerResult {
+	result := aggregate(ck.aggregator, ck.critical.Check(ctx))
+	if result.Status == StatusDown && ck.skipOnDown {
+		return result
+	}
+	return mergeResults(result, ck.rest.Check(ctx))
+}
+
+// mergeResults combines the results of two checkers. The aggregated status is
+// the worst of both statuses.
+func mergeResults(a, b CheckerResult) CheckerResult {
+	result := CheckerResult{
+		Status:  worstStatus(a.Status, b.Status),
+		Details: make(map[string]CheckResult, len(a.Details)+len(b.Details)),
+	}
+	for _, r := range []CheckerResult{a, b} {
+		for name, check := range r.Details {
+			result.Details[name] = check
+		}
+	}
+	return result
+}
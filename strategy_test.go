@@ -0,0 +1,46 @@
+package healthcheck
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestCriticalFirstUsesGlobalOptions(t *testing.T) {
+	statuses := make(chan AvailabilityStatus, 10)
+	config := InitChecker()
+	config.MustAdd(WithTimeout(50 * time.Millisecond))
+	config.MustAdd(WithStatusListener(func(ctx context.Context, status AvailabilityStatus) {
+		statuses <- status
+	}))
+	config.MustAdd(WithCheck(Check{Name: "cache", Check: func(ctx context.Context) error { return nil }}))
+	if err := config.AddCriticalCheck(Check{Name: "database", Check: func(ctx context.Context) error {
+		<-ctx.Done()
+		return errors.New("database unreachable")
+	}}); err != nil {
+		t.Fatal(err)
+	}
+	config.SetCriticalFirst(true)
+	handler := config.GetCheckerHandler()
+
+	start := time.Now()
+	rec := httptest.NewRecorder()
+	handler(rec, httptest.NewRequest(http.MethodGet, "/health", nil))
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("evaluation took %s, want the global timeout of 50ms", elapsed)
+	}
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Errorf("status code %d, want %d", rec.Code, http.StatusServiceUnavailable)
+	}
+	select {
+	case status := <-statuses:
+		if status != StatusDown {
+			t.Errorf("listener got status %q, want %q", status, StatusDown)
+		}
+	default:
+		t.Error("status listener not invoked for the critical check")
+	}
+}
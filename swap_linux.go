@@ -0,0 +1,81 @@
+package healthcheck
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// SwapUsageCheck returns a Check that fails if more than maxUsedPercent of the
+// host swap space is in use, or if pages are swapped in faster than
+// maxSwapInPerSecond. A threshold of 0 disables the respective check. The
+// swap-in rate is computed between two consecutive executions of the check.
+func SwapUsageCheck(maxUsedPercent float64, maxSwapInPerSecond float64) func(ctx context.Context) error {
+	var (
+		mtx        sync.Mutex
+		lastSwapIn uint64
+		lastTime   time.Time
+	)
+	return func(ctx context.Context) error {
+		if maxUsedPercent > 0 {
+			meminfo, err := readProcFields("/proc/meminfo")
+			if err != nil {
+				return err
+			}
+			total, free := meminfo["SwapTotal"], meminfo["SwapFree"]
+			if total > 0 {
+				used := float64(total-free) / float64(total) * 100
+				if used > maxUsedPercent {
+					return fmt.Errorf("swap usage %.1f%% > %.1f%%", used, maxUsedPercent)
+				}
+			}
+		}
+		if maxSwapInPerSecond > 0 {
+			vmstat, err := readProcFields("/proc/vmstat")
+			if err != nil {
+				return err
+			}
+			swapIn, now := vmstat["pswpin"], time.Now()
+			mtx.Lock()
+			prevSwapIn, prevTime := lastSwapIn, lastTime
+			lastSwapIn, lastTime = swapIn, now
+			mtx.Unlock()
+			if !prevTime.IsZero() && swapIn >= prevSwapIn {
+				rate := float64(swapIn-prevSwapIn) / now.Sub(prevTime).Seconds()
+				if rate > maxSwapInPerSecond {
+					return fmt.Errorf("swap-in rate %.1f pages/s > %.1f pages/s", rate, maxSwapInPerSecond)
+				}
+			}
+		}
+		return nil
+	}
+}
+
+// readProcFields parses files such as /proc/meminfo or /proc/vmstat that hold
+// one "key value [unit]" pair per line. Values are returned without unit.
+func readProcFields(path string) (map[string]uint64, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	fields := map[string]uint64{}
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		parts := strings.Fields(scanner.Text())
+		if len(parts) < 2 {
+			continue
+		}
+		value, err := strconv.ParseUint(parts[1], 10, 64)
+		if err != nil {
+			continue
+		}
+		fields[strings.TrimSuffix(parts[0], ":")] = value
+	}
+	return fields, scanner.Err()
+}
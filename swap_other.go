@@ -0,0 +1,14 @@
+//go:build !linux
+
+package healthcheck
+
+import "context"
+
+// SwapUsageCheck returns a Check that fails if more than maxUsedPercent of the
+// host swap space is in use, or if pages are swapped in faster than
+// maxSwapInPerSecond. It is only supported on Linux.
+func SwapUsageCheck(maxUsedPercent float64, maxSwapInPerSecond float64) func(ctx context.Context) error {
+	return func(ctx context.Context) error {
+		return errUnsupportedPlatform
+	}
+}
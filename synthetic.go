@@ -0,0 +1,189 @@
+package healthcheck
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/cookiejar"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SyntheticStep is one HTTP request of a SyntheticTransactionCheck. URL,
+// header values and body may reference variables as ${name}.
+type SyntheticStep struct {
+	// Name identifies the step in error messages.
+	Name string `json:"name"`
+	// Method defaults to GET.
+	Method  string            `json:"method"`
+	URL     string            `json:"url"`
+	Headers map[string]string `json:"headers"`
+	Body    string            `json:"body"`
+	// ExpectStatus is the expected status code. Default is 200.
+	ExpectStatus int `json:"expectStatus"`
+	// ExpectBody is a substring the response body must contain.
+	ExpectBody string `json:"expectBody"`
+	// Extract stores values of the response in variables for the following
+	// steps. It maps a variable name to one of the following sources:
+	//	header:<name>  a response header
+	//	json:<path>    a dot separated path into the JSON body, e.g. "json:data.items.0.id"
+	//	regexp:<expr>  the first submatch of a regular expression in the body
+	Extract map[string]string `json:"extract"`
+}
+
+var syntheticVariable = regexp.MustCompile(`\$\{(\w+)\}`)
+
+// SyntheticTransactionCheck returns a Check that executes the steps one after
+// another, sharing cookies and variables between them, and fails on the first
+// step that does not meet its expectations. This expresses user journeys
+// such as "login, fetch a resource, check its content" as a single check.
+// variables holds the initial variables, e.g. credentials.
+func SyntheticTransactionCheck(steps []SyntheticStep, variables map[string]string, timeout time.Duration) func(ctx context.Context) error {
+	return func(ctx context.Context) error {
+		ctx, cancel := withOptionalTimeout(ctx, timeout)
+		defer cancel()
+		jar, err := cookiejar.New(nil)
+		if err != nil {
+			return err
+		}
+		client := &http.Client{Jar: jar}
+		vars := map[string]string{}
+		for name, value := range variables {
+			vars[name] = value
+		}
+		for i, step := range steps {
+			name := step.Name
+			if name == "" {
+				name = strconv.Itoa(i + 1)
+			}
+			if err := runSyntheticStep(ctx, client, step, vars); err != nil {
+				return fmt.Errorf("step %s: %w", name, err)
+			}
+		}
+		return nil
+	}
+}
+
+func runSyntheticStep(ctx context.Context, client *http.Client, step SyntheticStep, vars map[string]string) error {
+	expand := func(s string) string {
+		return syntheticVariable.ReplaceAllStringFunc(s, func(ref string) string {
+			return vars[ref[2:len(ref)-1]]
+		})
+	}
+	method := step.Method
+	if method == "" {
+		method = http.MethodGet
+	}
+	req, err := http.NewRequestWithContext(ctx, method, expand(step.URL), strings.NewReader(expand(step.Body)))
+	if err != nil {
+		return err
+	}
+	for key, value := range step.Headers {
+		req.Header.Set(key, expand(value))
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 10<<20))
+	if err != nil {
+		return err
+	}
+	expectStatus := step.ExpectStatus
+	if expectStatus == 0 {
+		expectStatus = 200
+	}
+	if resp.StatusCode != expectStatus {
+		return fmt.Errorf("returned status %d, expected %d", resp.StatusCode, expectStatus)
+	}
+	if step.ExpectBody != "" && !bytes.Contains(body, []byte(expand(step.ExpectBody))) {
+		return fmt.Errorf("response body does not contain %q", expand(step.ExpectBody))
+	}
+	for name, source := range step.Extract {
+		value, err := extractSyntheticValue(resp, body, source)
+		if err != nil {
+			return fmt.Errorf("extracting %s: %w", name, err)
+		}
+		vars[name] = value
+	}
+	return nil
+}
+
+func extractSyntheticValue(resp *http.Response, body []byte, source string) (string, error) {
+	kind, expr, _ := strings.Cut(source, ":")
+	switch kind {
+	case "header":
+		if value := resp.Header.Get(expr); value != "" {
+			return value, nil
+		}
+		return "", fmt.Errorf("header %s not found", expr)
+	case "json":
+		return lookupJSONString(body, expr)
+	case "regexp":
+		re, err := regexp.Compile(expr)
+		if err != nil {
+			return "", err
+		}
+		match := re.FindSubmatch(body)
+		if len(match) < 2 {
+			return "", fmt.Errorf("%s does not match", expr)
+		}
+		return string(match[1]), nil
+	default:
+		return "", fmt.Errorf("unknown source %q", source)
+	}
+}
+
+// lookupJSONString resolves path in the JSON document body and returns the
+// value as string. Values other than strings are returned JSON encoded.
+func lookupJSONString(body []byte, path string) (string, error) {
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+	var document interface{}
+	if err := decoder.Decode(&document); err != nil {
+		return "", err
+	}
+	value, err := lookupJSONPath(document, path)
+	if err != nil {
+		return "", err
+	}
+	if s, ok := value.(string); ok {
+		return s, nil
+	}
+	encoded, err := json.Marshal(value)
+	return string(encoded), err
+}
+
+// lookupJSONPath resolves a dot separated path (e.g., "data.items.0.id") in
+// a decoded JSON document.
+func lookupJSONPath(document interface{}, path string) (interface{}, error) {
+	value := document
+	if path == "" {
+		return value, nil
+	}
+	for _, key := range strings.Split(path, ".") {
+		switch v := value.(type) {
+		case map[string]interface{}:
+			next, ok := v[key]
+			if !ok {
+				return nil, fmt.Errorf("%s not found", path)
+			}
+			value = next
+		case []interface{}:
+			index, err := strconv.Atoi(key)
+			if err != nil || index < 0 || index >= len(v) {
+				return nil, fmt.Errorf("%s not found", path)
+			}
+			value = v[index]
+		default:
+			return nil, fmt.Errorf("%s not found", path)
+		}
+	}
+	return value, nil
+}
@@ -0,0 +1,62 @@
+package healthcheck
+
+import (
+	"net/http"
+	"strings"
+)
+
+// SetCheckTags assigns tags (e.g., "db", "cache" or "external") to the named
+// check, replacing its previous tags. Callers of the checker handler can
+// request the checks with any of a set of tags with the query parameter
+// tags, e.g. /health?tags=db,cache; the status is then aggregated from these
+// checks only. See GetTaggedHandler for a handler that evaluates no other
+// checks.
+func (c *AndictlCheckerConfig) SetCheckTags(name string, tags ...string) {
+	if c.tags == nil {
+		c.tags = map[string][]string{}
+	}
+	c.tags[name] = tags
+}
+
+// GetTaggedHandler returns a handler like GetCheckerHandler that only
+// evaluates the checks with any of tags.
+func (c AndictlCheckerConfig) GetTaggedHandler(tags ...string) http.HandlerFunc {
+	checkTags := c.tags
+	c.include = func(name string) bool {
+		return hasAnyTag(checkTags[name], tags)
+	}
+	// The results of a group do not describe the whole system.
+	c.snapshots = nil
+	return c.GetCheckerHandler()
+}
+
+// filterByTags returns the results of the checks with any of the
+// comma-separated tags, with the status aggregated from these results only.
+func (c AndictlCheckerConfig) filterByTags(result CheckerResult, tags string) CheckerResult {
+	requested := strings.Split(tags, ",")
+	for i := range requested {
+		requested[i] = strings.TrimSpace(requested[i])
+	}
+	filtered := CheckerResult{Status: StatusUp, Details: map[string]CheckResult{}}
+	for name, check := range result.Details {
+		if hasAnyTag(c.tags[name], requested) {
+			filtered.Details[name] = check
+		}
+	}
+	aggregator := c.aggregator
+	if aggregator == nil {
+		aggregator = WorstOfAggregator{}
+	}
+	return aggregate(aggregator, filtered)
+}
+
+func hasAnyTag(tags, wanted []string) bool {
+	for _, tag := range tags {
+		for _, w := range wanted {
+			if tag == w {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,83 @@
+package healthcheck
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// temporalWorkflowService is the gRPC service of the Temporal frontend.
+const temporalWorkflowService = "temporal.api.workflowservice.v1.WorkflowService"
+
+// States of a Temporal namespace.
+var temporalNamespaceStates = map[uint64]string{
+	0: "UNSPECIFIED",
+	1: "REGISTERED",
+	2: "DEPRECATED",
+	3: "DELETED",
+}
+
+// TemporalCheck returns a Check that queries the health of the workflow
+// service of the Temporal frontend at addr (e.g., "localhost:7233") and
+// fails unless it is SERVING and namespace is registered. Workers of a
+// deleted or deprecated namespace can no longer start workflows. options
+// can carry an API key in the Metadata, e.g. for Temporal Cloud.
+func TemporalCheck(addr, namespace string, options GRPCOptions, timeout time.Duration) func(ctx context.Context) error {
+	conn := newGRPCConn(addr, options)
+	return func(ctx context.Context) error {
+		ctx, cancel := withOptionalTimeout(ctx, timeout)
+		defer cancel()
+		if err := grpcHealth(ctx, conn, temporalWorkflowService); err != nil {
+			return err
+		}
+		request := appendProtoString(nil, 1, namespace)
+		responses, err := conn.call(ctx, "/"+temporalWorkflowService+"/DescribeNamespace", request)
+		var status *grpcStatusError
+		if errors.As(err, &status) && status.code == grpcNotFound {
+			return fmt.Errorf("namespace %q not found", namespace)
+		}
+		if err != nil {
+			return err
+		}
+		if len(responses) != 1 {
+			return fmt.Errorf("expected one response, got %d", len(responses))
+		}
+		state, err := temporalNamespaceState(responses[0])
+		if err != nil {
+			return err
+		}
+		if state != 1 {
+			name, ok := temporalNamespaceStates[state]
+			if !ok {
+				name = fmt.Sprint(state)
+			}
+			return fmt.Errorf("namespace %q is %s", namespace, name)
+		}
+		return nil
+	}
+}
+
+// temporalNamespaceState returns the state of the namespace_info of a
+// DescribeNamespaceResponse.
+func temporalNamespaceState(response []byte) (uint64, error) {
+	fields, err := parseProto(response)
+	if err != nil {
+		return 0, err
+	}
+	for _, field := range fields {
+		if field.number != 1 { // namespace_info
+			continue
+		}
+		info, err := parseProto(field.bytes)
+		if err != nil {
+			return 0, err
+		}
+		for _, f := range info {
+			if f.number == 2 { // state
+				return f.varint, nil
+			}
+		}
+	}
+	return 0, nil
+}
@@ -0,0 +1,16 @@
+package healthcheck
+
+import (
+	"context"
+	"time"
+)
+
+// withOptionalTimeout is like context.WithTimeout, but a zero or negative
+// timeout leaves the deadline of ctx, e.g. the check specific or global
+// timeout, unchanged instead of expiring immediately.
+func withOptionalTimeout(ctx context.Context, timeout time.Duration) (context.Context, context.CancelFunc) {
+	if timeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, timeout)
+}
@@ -0,0 +1,92 @@
+package healthcheck
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestWithOptionalTimeout(t *testing.T) {
+	parent, cancel := context.WithTimeout(context.Background(), time.Hour)
+	defer cancel()
+	parentDeadline, _ := parent.Deadline()
+	tests := []struct {
+		timeout time.Duration
+		want    time.Time
+	}{
+		{0, parentDeadline},
+		{-time.Second, parentDeadline},
+		{time.Minute, time.Now().Add(time.Minute)},
+	}
+	for _, test := range tests {
+		ctx, cancel := withOptionalTimeout(parent, test.timeout)
+		deadline, ok := ctx.Deadline()
+		cancel()
+		if !ok || deadline.Sub(test.want) > time.Second || test.want.Sub(deadline) > time.Second {
+			t.Errorf("timeout %s: deadline %s, want %s", test.timeout, deadline, test.want)
+		}
+		if ctx.Err() == nil {
+			t.Errorf("timeout %s: context not canceled by cancel", test.timeout)
+		}
+	}
+}
+
+// memoryStore is an ObjectStore backed by a map.
+type memoryStore struct {
+	mtx     sync.Mutex
+	objects map[string][]byte
+}
+
+func (s *memoryStore) Put(ctx context.Context, key string, data []byte) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	s.objects[key] = data
+	return nil
+}
+
+func (s *memoryStore) Get(ctx context.Context, key string) ([]byte, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	data, ok := s.objects[key]
+	if !ok {
+		return nil, errors.New("not found")
+	}
+	return data, nil
+}
+
+func (s *memoryStore) Delete(ctx context.Context, key string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	delete(s.objects, key)
+	return nil
+}
+
+func TestBucketCanaryCheckWithoutTimeout(t *testing.T) {
+	store := &memoryStore{objects: map[string][]byte{}}
+	if err := BucketCanaryCheck(store, "healthcheck/", 0)(context.Background()); err != nil {
+		t.Errorf("BucketCanaryCheck with zero timeout: %v", err)
+	}
+	if len(store.objects) != 0 {
+		t.Errorf("%d objects left behind", len(store.objects))
+	}
+}
+
+func TestQueueDepthCheckWithoutTimeout(t *testing.T) {
+	check := QueueDepthCheck(func(ctx context.Context) (int64, error) {
+		return 3, ctx.Err()
+	}, 10, 0)
+	if err := check(context.Background()); err != nil {
+		t.Errorf("QueueDepthCheck with zero timeout: %v", err)
+	}
+}
@@ -0,0 +1,365 @@
+package healthcheck
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"time"
+
+	"golang.org/x/crypto/ocsp"
+)
+
+// RevocationCheckOptions configures the certificate revocation checks.
+type RevocationCheckOptions struct {
+	// FailOnUnreachable fails the check if the revocation status cannot be
+	// determined (e.g., the OCSP responder is unreachable). Otherwise the
+	// check reports the system as degraded (see Degraded).
+	FailOnUnreachable bool
+	// TLS is the configuration used for the handshake of TLSRevocationCheck.
+	TLS *tls.Config
+	// Timeout for the whole check. If zero, the deadline of the check
+	// context applies.
+	Timeout time.Duration
+}
+
+// TLSRevocationCheck returns a Check that performs a TLS handshake against
+// addr and verifies that the leaf certificate presented by the server is not
+// revoked. A stapled OCSP response is used when present; otherwise the OCSP
+// responder of the certificate is queried, falling back to its CRL.
+func TLSRevocationCheck(addr string, options RevocationCheckOptions) func(ctx context.Context) error {
+	return func(ctx context.Context) error {
+		ctx, cancel := withOptionalTimeout(ctx, options.Timeout)
+		defer cancel()
+		state, err := tlsHandshake(ctx, addr, options.TLS)
+		if err != nil {
+			return err
+		}
+		if len(state.PeerCertificates) < 2 {
+			return fmt.Errorf("server did not present the issuer certificate")
+		}
+		leaf, issuer := state.PeerCertificates[0], state.PeerCertificates[1]
+		if len(state.OCSPResponse) > 0 {
+			resp, err := ocsp.ParseResponseForCert(state.OCSPResponse, leaf, issuer)
+			if err == nil {
+				return ocspStatusError(resp)
+			}
+		}
+		return checkRevocation(ctx, leaf, issuer, options.FailOnUnreachable)
+	}
+}
+
+// CertificateFileRevocationCheck returns a Check that verifies that the
+// PEM encoded certificate in certFile, issued by the certificate in
+// issuerFile, is not revoked.
+func CertificateFileRevocationCheck(certFile, issuerFile string, options RevocationCheckOptions) func(ctx context.Context) error {
+	return func(ctx context.Context) error {
+		ctx, cancel := withOptionalTimeout(ctx, options.Timeout)
+		defer cancel()
+		leaf, err := readCertificateFile(certFile)
+		if err != nil {
+			return err
+		}
+		issuer, err := readCertificateFile(issuerFile)
+		if err != nil {
+			return err
+		}
+		return checkRevocation(ctx, leaf, issuer, options.FailOnUnreachable)
+	}
+}
+
+// tlsHandshake connects to addr and returns the state of the completed TLS
+// handshake. A nil config verifies the server against the system roots.
+func tlsHandshake(ctx context.Context, addr string, config *tls.Config) (tls.ConnectionState, error) {
+	if config == nil {
+		config = &tls.Config{}
+	} else {
+		config = config.Clone()
+	}
+	if config.ServerName == "" {
+		host, _, err := net.SplitHostPort(addr)
+		if err != nil {
+			return tls.ConnectionState{}, err
+		}
+		config.ServerName = host
+	}
+	dialer := tls.Dialer{Config: config}
+	conn, err := dialer.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return tls.ConnectionState{}, err
+	}
+	defer conn.Close()
+	return conn.(*tls.Conn).ConnectionState(), nil
+}
+
+// readCertificateFile parses the first PEM encoded certificate in path.
+func readCertificateFile(path string) (*x509.Certificate, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	for {
+		var block *pem.Block
+		block, content = pem.Decode(content)
+		if block == nil {
+			return nil, fmt.Errorf("no certificate found in %s", path)
+		}
+		if block.Type == "CERTIFICATE" {
+			return x509.ParseCertificate(block.Bytes)
+		}
+	}
+}
+
+// checkRevocation queries the OCSP responder of leaf or, if it has none, its
+// CRL distribution point.
+func checkRevocation(ctx context.Context, leaf, issuer *x509.Certificate, failOnUnreachable bool) error {
+	unreachable := func(err error) error {
+		if failOnUnreachable {
+			return err
+		}
+		return Degraded(err)
+	}
+	switch {
+	case len(leaf.OCSPServer) > 0:
+		resp, err := queryOCSP(ctx, leaf.OCSPServer[0], leaf, issuer)
+		if err != nil {
+			return unreachable(fmt.Errorf("OCSP request failed: %w", err))
+		}
+		return ocspStatusError(resp)
+	case len(leaf.CRLDistributionPoints) > 0:
+		revoked, err := queryCRL(ctx, leaf.CRLDistributionPoints[0], leaf, issuer)
+		if err != nil {
+			return unreachable(fmt.Errorf("CRL fetch failed: %w", err))
+		}
+		if revoked {
+			return fmt.Errorf("certificate %s is revoked", leaf.Subject)
+		}
+		return nil
+	default:
+		return unreachable(fmt.Errorf("certificate %s has neither OCSP responder nor CRL", leaf.Subject))
+	}
+}
+
+func queryOCSP(ctx context.Context, server string, leaf, issuer *x509.Certificate) (*ocsp.Response, error) {
+	body, err := ocsp.CreateRequest(leaf, issuer, nil)
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, server, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/ocsp-request")
+	content, err := fetch(req)
+	if err != nil {
+		return nil, err
+	}
+	return ocsp.ParseResponseForCert(content, leaf, issuer)
+}
+
+func queryCRL(ctx context.Context, url string, leaf, issuer *x509.Certificate) (bool, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return false, err
+	}
+	content, err := fetch(req)
+	if err != nil {
+		return false, err
+	}
+	crl, err := x509.ParseCRL(content)
+	if err != nil {
+		return false, err
+	}
+	if err := issuer.CheckCRLSignature(crl); err != nil {
+		return false, err
+	}
+	for _, revoked := range crl.TBSCertList.RevokedCertificates {
+		if revoked.SerialNumber.Cmp(leaf.SerialNumber) == 0 {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func fetch(req *http.Request) ([]byte, error) {
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != 200 {
+		return nil, fmt.Errorf("returned status %d", resp.StatusCode)
+	}
+	return io.ReadAll(io.LimitReader(resp.Body, 10<<20))
+}
+
+func ocspStatusError(resp *ocsp.Response) error {
+	switch resp.Status {
+	case ocsp.Good:
+		return nil
+	case ocsp.Revoked:
+		return fmt.Errorf("certificate was revoked at %s", resp.RevokedAt)
+	default:
+		return fmt.Errorf("OCSP responder does not know the certificate")
+	}
+}
+
+// TLSPolicy is the security baseline enforced by TLSPolicyCheck.
+type TLSPolicy struct {
+	// MinVersion is the lowest acceptable TLS version. Default is
+	// tls.VersionTLS12.
+	MinVersion uint16
+	// AllowedCipherSuites restricts the acceptable cipher suites. If empty,
+	// every cipher suite except the ones considered insecure (see
+	// tls.InsecureCipherSuites) is acceptable. TLS 1.3 cipher suites are
+	// always acceptable.
+	AllowedCipherSuites []uint16
+}
+
+// TLSPolicyCheck returns a Check that performs a TLS handshake against addr
+// and fails if the negotiated TLS version or cipher suite does not comply
+// with policy. The handshake offers legacy versions and cipher suites, so the
+// result reflects what the server is willing to accept.
+func TLSPolicyCheck(addr string, policy TLSPolicy, timeout time.Duration) func(ctx context.Context) error {
+	if policy.MinVersion == 0 {
+		policy.MinVersion = tls.VersionTLS12
+	}
+	config := &tls.Config{MinVersion: tls.VersionTLS10}
+	insecure := map[uint16]bool{}
+	for _, suite := range tls.CipherSuites() {
+		config.CipherSuites = append(config.CipherSuites, suite.ID)
+	}
+	for _, suite := range tls.InsecureCipherSuites() {
+		config.CipherSuites = append(config.CipherSuites, suite.ID)
+		insecure[suite.ID] = true
+	}
+	allowed := map[uint16]bool{}
+	for _, id := range policy.AllowedCipherSuites {
+		allowed[id] = true
+	}
+	return func(ctx context.Context) error {
+		ctx, cancel := withOptionalTimeout(ctx, timeout)
+		defer cancel()
+		state, err := tlsHandshake(ctx, addr, config)
+		if err != nil {
+			return err
+		}
+		if state.Version < policy.MinVersion {
+			return fmt.Errorf("negotiated %s, policy requires at least %s",
+				tlsVersionName(state.Version), tlsVersionName(policy.MinVersion))
+		}
+		if state.Version == tls.VersionTLS13 {
+			return nil
+		}
+		if len(allowed) > 0 && !allowed[state.CipherSuite] || len(allowed) == 0 && insecure[state.CipherSuite] {
+			return fmt.Errorf("negotiated cipher suite %s is not allowed by policy", tls.CipherSuiteName(state.CipherSuite))
+		}
+		return nil
+	}
+}
+
+// CertificatePinCheck returns a Check that performs a TLS handshake against
+// addr and fails unless a certificate of the presented chain matches one of
+// the pinned SPKI fingerprints. Pins are base64 encoded SHA-256 digests of the
+// DER encoded SubjectPublicKeyInfo, as used by "pin-sha256". A nil config
+// verifies the server against the system roots.
+func CertificatePinCheck(addr string, pins []string, config *tls.Config, timeout time.Duration) func(ctx context.Context) error {
+	pinned := map[string]bool{}
+	for _, pin := range pins {
+		pinned[pin] = true
+	}
+	return func(ctx context.Context) error {
+		ctx, cancel := withOptionalTimeout(ctx, timeout)
+		defer cancel()
+		state, err := tlsHandshake(ctx, addr, config)
+		if err != nil {
+			return err
+		}
+		for _, cert := range state.PeerCertificates {
+			digest := sha256.Sum256(cert.RawSubjectPublicKeyInfo)
+			if pinned[base64.StdEncoding.EncodeToString(digest[:])] {
+				return nil
+			}
+		}
+		if len(state.PeerCertificates) == 0 {
+			return fmt.Errorf("server presented no certificate")
+		}
+		leaf := state.PeerCertificates[0]
+		digest := sha256.Sum256(leaf.RawSubjectPublicKeyInfo)
+		return fmt.Errorf("no pinned key in certificate chain of %s (leaf pin-sha256 %s)",
+			leaf.Subject, base64.StdEncoding.EncodeToString(digest[:]))
+	}
+}
+
+// TLSCertificateCheck returns a Check that performs a TLS handshake against
+// addr and fails if the leaf certificate presented by the server expires
+// within warnBefore. Certificates that are already expired, not yet valid or
+// untrusted fail the handshake. A nil config verifies the server against the
+// system roots.
+func TLSCertificateCheck(addr string, warnBefore time.Duration, config *tls.Config, timeout time.Duration) func(ctx context.Context) error {
+	return func(ctx context.Context) error {
+		ctx, cancel := withOptionalTimeout(ctx, timeout)
+		defer cancel()
+		state, err := tlsHandshake(ctx, addr, config)
+		if err != nil {
+			return err
+		}
+		if len(state.PeerCertificates) == 0 {
+			return fmt.Errorf("server presented no certificate")
+		}
+		return certificateExpiryError(state.PeerCertificates[0], warnBefore)
+	}
+}
+
+// CertificateFileCheck returns a Check that parses the PEM encoded
+// certificate in the file at path and fails if it is not yet valid, has
+// expired or expires within warnBefore. The file is read on every execution,
+// so renewed certificates are picked up.
+func CertificateFileCheck(path string, warnBefore time.Duration) func(ctx context.Context) error {
+	return func(ctx context.Context) error {
+		cert, err := readCertificateFile(path)
+		if err != nil {
+			return err
+		}
+		if time.Now().Before(cert.NotBefore) {
+			return fmt.Errorf("certificate %s is not valid before %s", cert.Subject, cert.NotBefore.Format("2006-01-02"))
+		}
+		return certificateExpiryError(cert, warnBefore)
+	}
+}
+
+// certificateExpiryError returns an error if cert expires within warnBefore.
+func certificateExpiryError(cert *x509.Certificate, warnBefore time.Duration) error {
+	remaining := time.Until(cert.NotAfter)
+	if remaining <= 0 {
+		return fmt.Errorf("certificate %s expired on %s", cert.Subject, cert.NotAfter.Format("2006-01-02"))
+	}
+	if remaining < warnBefore {
+		return fmt.Errorf("certificate %s expires on %s (in %s)",
+			cert.Subject, cert.NotAfter.Format("2006-01-02"), remaining.Round(time.Hour))
+	}
+	return nil
+}
+
+func tlsVersionName(version uint16) string {
+	switch version {
+	case tls.VersionTLS10:
+		return "TLS 1.0"
+	case tls.VersionTLS11:
+		return "TLS 1.1"
+	case tls.VersionTLS12:
+		return "TLS 1.2"
+	case tls.VersionTLS13:
+		return "TLS 1.3"
+	default:
+		return fmt.Sprintf("0x%04X", version)
+	}
+}
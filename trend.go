@@ -0,0 +1,101 @@
+package healthcheck
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Trend describes the direction of the most recent status transition of a
+// check or of the whole system.
+type Trend string
+
+const (
+	// TrendStable means there was no status transition within the trend
+	// window.
+	TrendStable Trend = "stable"
+	// TrendRecovering means the status recently changed for the better (e.g.,
+	// from "down" to "up").
+	TrendRecovering Trend = "recovering"
+	// TrendDegrading means the status recently changed for the worse (e.g.,
+	// from "up" to "down").
+	TrendDegrading Trend = "degrading"
+)
+
+// overallTrendKey is the key under which the trend tracker stores the
+// aggregated system status. Check names cannot be empty, so it cannot clash.
+const overallTrendKey = ""
+
+type transition struct {
+	status  AvailabilityStatus
+	trend   Trend
+	movedAt time.Time
+}
+
+// trendTracker records the last status transition of every check and of the
+// aggregated status.
+type trendTracker struct {
+	mtx         sync.Mutex
+	window      time.Duration
+	transitions map[string]transition
+}
+
+func newTrendTracker(window time.Duration) *trendTracker {
+	return &trendTracker{window: window, transitions: map[string]transition{}}
+}
+
+func (t *trendTracker) observe(result CheckerResult) {
+	t.mtx.Lock()
+	defer t.mtx.Unlock()
+	now := time.Now()
+	t.record(overallTrendKey, result.Status, now)
+	if result.Details != nil {
+		for name, check := range result.Details {
+			t.record(name, check.Status, now)
+		}
+	}
+}
+
+func (t *trendTracker) record(name string, status AvailabilityStatus, now time.Time) {
+	last, ok := t.transitions[name]
+	if !ok {
+		t.transitions[name] = transition{status: status, trend: TrendStable}
+		return
+	}
+	if last.status == status {
+		return
+	}
+	next := transition{status: status, trend: TrendStable, movedAt: now}
+	// The first result after startup is no real transition.
+	if last.status != StatusUnknown {
+		if criticality(status) < criticality(last.status) {
+			next.trend = TrendRecovering
+		} else {
+			next.trend = TrendDegrading
+		}
+	}
+	t.transitions[name] = next
+}
+
+func (t *trendTracker) trend(name string) Trend {
+	t.mtx.Lock()
+	defer t.mtx.Unlock()
+	last, ok := t.transitions[name]
+	if !ok || time.Since(last.movedAt) > t.window {
+		return TrendStable
+	}
+	return last.trend
+}
+
+// trendChecker feeds every result of the wrapped checker into a trend
+// tracker.
+type trendChecker struct {
+	engine
+	tracker *trendTracker
+}
+
+func (ck *trendChecker) Check(ctx context.Context) CheckerResult {
+	result := ck.engine.Check(ctx)
+	ck.tracker.observe(result)
+	return result
+}
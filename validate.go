@@ -0,0 +1,140 @@
+package healthcheck
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrInvalidConfig is wrapped by all errors reporting an invalid checker
+// configuration.
+var ErrInvalidConfig = errors.New("invalid health check configuration")
+
+func invalidConfig(format string, args ...interface{}) error {
+	return fmt.Errorf("%w: %s", ErrInvalidConfig, fmt.Sprintf(format, args...))
+}
+
+// MustAdd is like AddCheck but panics if the option is invalid. It suits
+// registrations at program start, where an invalid check is a programming
+// error.
+func (c *AndictlCheckerConfig) MustAdd(option Option) {
+	if err := c.AddCheck(option); err != nil {
+		panic(err)
+	}
+}
+
+// Validate reports the first problem of the whole configuration: checks
+// without a name or function, duplicate check names, negative timeouts, and
+// weights, priorities, severities, tags, probes or dependencies set for
+// checks that are not registered, dependency cycles, and negative intervals
+// or jitter.
+func (c AndictlCheckerConfig) Validate() error {
+	cfg, err := c.engineConfig()
+	if err != nil {
+		return err
+	}
+	names := map[string]bool{}
+	for _, check := range cfg.checks {
+		names[check.Name] = true
+	}
+	for name, weight := range c.weights {
+		if !names[name] {
+			return invalidConfig("weight set for unknown check %q", name)
+		}
+		if weight < 0 {
+			return invalidConfig("check %q: negative weight %v", name, weight)
+		}
+	}
+	for name := range c.priorities {
+		if !names[name] {
+			return invalidConfig("priority set for unknown check %q", name)
+		}
+	}
+	for name := range c.severities {
+		if !names[name] {
+			return invalidConfig("severity set for unknown check %q", name)
+		}
+	}
+	for name := range c.tags {
+		if !names[name] {
+			return invalidConfig("tags set for unknown check %q", name)
+		}
+	}
+	for name := range c.probes {
+		if !names[name] {
+			return invalidConfig("probes set for unknown check %q", name)
+		}
+	}
+	for name, dependencies := range c.dependencies {
+		if !names[name] {
+			return invalidConfig("dependencies set for unknown check %q", name)
+		}
+		for _, dependency := range dependencies {
+			if !names[dependency] {
+				return invalidConfig("check %q depends on unknown check %q", name, dependency)
+			}
+		}
+	}
+	if cycle := dependencyCycle(c.dependencies); cycle != nil {
+		return invalidConfig("dependency cycle %s", strings.Join(cycle, " -> "))
+	}
+	if c.backgroundInterval < 0 {
+		return invalidConfig("negative background interval %s", c.backgroundInterval)
+	}
+	if c.scheduleJitter < 0 {
+		return invalidConfig("negative schedule jitter %s", c.scheduleJitter)
+	}
+	if c.budget < 0 {
+		return invalidConfig("negative evaluation budget %s", c.budget)
+	}
+	return nil
+}
+
+// engineConfig returns the validated configuration of all registered
+// options.
+func (c AndictlCheckerConfig) engineConfig(extra ...Option) (engineConfig, error) {
+	options := make([]Option, 0, len(c.checkers)+len(c.critical)+len(extra))
+	options = append(options, c.checkers...)
+	options = append(options, c.critical...)
+	options = append(options, extra...)
+	cfg := newEngineConfig(options...)
+	return cfg, validateEngineConfig(cfg)
+}
+
+func validateEngineConfig(cfg engineConfig) error {
+	if cfg.timeout <= 0 {
+		return invalidConfig("timeout must be positive, got %s", cfg.timeout)
+	}
+	if cfg.cacheDuration < 0 {
+		return invalidConfig("negative cache duration %s", cfg.cacheDuration)
+	}
+	if cfg.startupGracePeriod < 0 {
+		return invalidConfig("negative startup grace period %s", cfg.startupGracePeriod)
+	}
+	names := map[string]bool{}
+	for _, check := range cfg.checks {
+		switch {
+		case check.Name == "":
+			return invalidConfig("check without name")
+		case names[check.Name]:
+			return invalidConfig("duplicate check %q", check.Name)
+		case check.Check.Check == nil:
+			return invalidConfig("check %q: nil check function", check.Name)
+		case check.Timeout < 0:
+			return invalidConfig("check %q: negative timeout %s", check.Name, check.Timeout)
+		case check.FailureThreshold < 0:
+			return invalidConfig("check %q: negative failure threshold %d", check.Name, check.FailureThreshold)
+		case check.SuccessThreshold < 0:
+			return invalidConfig("check %q: negative success threshold %d", check.Name, check.SuccessThreshold)
+		case check.refreshPeriod < 0 || check.initialDelay < 0:
+			return invalidConfig("check %q: negative refresh period or initial delay", check.Name)
+		}
+		names[check.Name] = true
+	}
+	for _, listener := range cfg.listeners {
+		if listener == nil {
+			return invalidConfig("nil status listener")
+		}
+	}
+	return nil
+}
@@ -0,0 +1,183 @@
+package healthcheck
+
+import (
+	"bufio"
+	"context"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/tls"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// webSocketGUID is appended to the key of the opening handshake to compute
+// the accept value of the server (RFC 6455, section 1.3).
+const webSocketGUID = "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"
+
+const (
+	webSocketClose = 0x8
+	webSocketPing  = 0x9
+	webSocketPong  = 0xA
+)
+
+// WebSocketCheck returns a Check that completes the opening handshake of a
+// WebSocket connection to rawURL (e.g., "wss://gateway/ws") and fails unless
+// the server accepts the upgrade. If ping is set, the check also sends a
+// ping frame and waits for the pong. wss URLs use TLS with config, or the
+// default configuration if config is nil.
+func WebSocketCheck(rawURL string, ping bool, config *tls.Config, timeout time.Duration) func(ctx context.Context) error {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return func(ctx context.Context) error {
+			return err
+		}
+	}
+	port := "80"
+	switch u.Scheme {
+	case "ws":
+		config = nil
+		u.Scheme = "http"
+	case "wss":
+		port = "443"
+		if config == nil {
+			config = &tls.Config{}
+		}
+		if config.ServerName == "" {
+			config = config.Clone()
+			config.ServerName = u.Hostname()
+		}
+		u.Scheme = "https"
+	default:
+		return func(ctx context.Context) error {
+			return fmt.Errorf("unsupported scheme %q", u.Scheme)
+		}
+	}
+	addr := u.Host
+	if u.Port() == "" {
+		addr = net.JoinHostPort(u.Hostname(), port)
+	}
+	requestURL := u.String()
+	return func(ctx context.Context) error {
+		ctx, cancel := withOptionalTimeout(ctx, timeout)
+		defer cancel()
+		conn, err := dialTCP(ctx, addr, config)
+		if err != nil {
+			return err
+		}
+		defer conn.Close()
+		nonce := make([]byte, 16)
+		if _, err := rand.Read(nonce); err != nil {
+			return err
+		}
+		key := base64.StdEncoding.EncodeToString(nonce)
+		req, err := http.NewRequest(http.MethodGet, requestURL, nil)
+		if err != nil {
+			return err
+		}
+		req.Header.Set("Upgrade", "websocket")
+		req.Header.Set("Connection", "Upgrade")
+		req.Header.Set("Sec-WebSocket-Key", key)
+		req.Header.Set("Sec-WebSocket-Version", "13")
+		if err := req.Write(conn); err != nil {
+			return err
+		}
+		reader := bufio.NewReader(conn)
+		resp, err := http.ReadResponse(reader, req)
+		if err != nil {
+			return err
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusSwitchingProtocols {
+			return fmt.Errorf("returned status %d", resp.StatusCode)
+		}
+		digest := sha1.Sum([]byte(key + webSocketGUID))
+		if resp.Header.Get("Sec-WebSocket-Accept") != base64.StdEncoding.EncodeToString(digest[:]) {
+			return errors.New("invalid Sec-WebSocket-Accept header")
+		}
+		if ping {
+			if err := writeWebSocketFrame(conn, webSocketPing, []byte("healthcheck")); err != nil {
+				return err
+			}
+			for {
+				opcode, payload, err := readWebSocketFrame(reader)
+				if err != nil {
+					return err
+				}
+				if opcode == webSocketClose {
+					return fmt.Errorf("server closed the connection: %s", webSocketCloseReason(payload))
+				}
+				if opcode == webSocketPong {
+					break
+				}
+			}
+		}
+		// Close status 1000 (normal closure).
+		return writeWebSocketFrame(conn, webSocketClose, []byte{0x03, 0xE8})
+	}
+}
+
+// writeWebSocketFrame writes a final, masked control frame.
+func writeWebSocketFrame(w io.Writer, opcode byte, payload []byte) error {
+	frame := []byte{0x80 | opcode, 0x80 | byte(len(payload)), 0, 0, 0, 0}
+	if _, err := rand.Read(frame[2:]); err != nil {
+		return err
+	}
+	for i, b := range payload {
+		frame = append(frame, b^frame[2+i%4])
+	}
+	_, err := w.Write(frame)
+	return err
+}
+
+// readWebSocketFrame reads an unmasked frame as sent by servers and returns
+// its opcode and payload. Fragmented messages are returned frame by frame.
+func readWebSocketFrame(r *bufio.Reader) (byte, []byte, error) {
+	header := make([]byte, 2)
+	if _, err := io.ReadFull(r, header); err != nil {
+		return 0, nil, err
+	}
+	length := uint64(header[1] & 0x7F)
+	switch length {
+	case 126:
+		extended := make([]byte, 2)
+		if _, err := io.ReadFull(r, extended); err != nil {
+			return 0, nil, err
+		}
+		length = uint64(binary.BigEndian.Uint16(extended))
+	case 127:
+		extended := make([]byte, 8)
+		if _, err := io.ReadFull(r, extended); err != nil {
+			return 0, nil, err
+		}
+		length = binary.BigEndian.Uint64(extended)
+	}
+	if header[1]&0x80 != 0 {
+		return 0, nil, errors.New("server sent a masked frame")
+	}
+	if length > 1<<20 {
+		return 0, nil, errors.New("WebSocket frame too large")
+	}
+	payload := make([]byte, length)
+	if _, err := io.ReadFull(r, payload); err != nil {
+		return 0, nil, err
+	}
+	return header[0] & 0x0F, payload, nil
+}
+
+func webSocketCloseReason(payload []byte) string {
+	if len(payload) < 2 {
+		return "no status"
+	}
+	reason := fmt.Sprintf("status %d", binary.BigEndian.Uint16(payload))
+	if len(payload) > 2 {
+		reason += " " + string(payload[2:])
+	}
+	return reason
+}
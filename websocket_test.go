@@ -0,0 +1,71 @@
+package healthcheck
+
+import (
+	"context"
+	"crypto/sha1"
+	"crypto/tls"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// webSocketEcho completes the opening handshake and answers one ping with a
+// pong carrying the same payload.
+func webSocketEcho(w http.ResponseWriter, r *http.Request) {
+	conn, rw, err := w.(http.Hijacker).Hijack()
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+	digest := sha1.Sum([]byte(r.Header.Get("Sec-WebSocket-Key") + webSocketGUID))
+	fmt.Fprintf(rw, "HTTP/1.1 101 Switching Protocols\r\nUpgrade: websocket\r\nConnection: Upgrade\r\nSec-WebSocket-Accept: %s\r\n\r\n",
+		base64.StdEncoding.EncodeToString(digest[:]))
+	rw.Flush()
+	header := make([]byte, 6)
+	if _, err := io.ReadFull(rw, header); err != nil {
+		return
+	}
+	payload := make([]byte, header[1]&0x7F)
+	if _, err := io.ReadFull(rw, payload); err != nil {
+		return
+	}
+	for i := range payload {
+		payload[i] ^= header[2+i%4]
+	}
+	rw.Write(append([]byte{0x80 | webSocketPong, byte(len(payload))}, payload...))
+	rw.Flush()
+}
+
+func TestWebSocketCheckConcurrent(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(webSocketEcho))
+	defer server.Close()
+	config := &tls.Config{InsecureSkipVerify: true}
+	check := WebSocketCheck(strings.Replace(server.URL, "https://", "wss://", 1)+"/ws", true, config, time.Second)
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := check(context.Background()); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+	if config.ServerName != "" {
+		t.Errorf("caller config modified: ServerName %q", config.ServerName)
+	}
+}
+
+func TestWebSocketCheckInvalidURL(t *testing.T) {
+	err := WebSocketCheck("http://gateway/ws", false, nil, time.Second)(context.Background())
+	if err == nil || !strings.Contains(err.Error(), `unsupported scheme "http"`) {
+		t.Errorf("error %v, want unsupported scheme", err)
+	}
+}
@@ -0,0 +1,60 @@
+package healthcheck
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// ZooKeeperCheck returns a Check that sends the four letter words "ruok" and
+// "srvr" to the ZooKeeper node at addr and fails unless the node answers
+// "imok" and serves requests in a mode other than read-only. Both commands
+// must be allowed by the 4lw.commands.whitelist setting of the node.
+func ZooKeeperCheck(addr string, timeout time.Duration) func(ctx context.Context) error {
+	return func(ctx context.Context) error {
+		ctx, cancel := withOptionalTimeout(ctx, timeout)
+		defer cancel()
+		reply, err := zooKeeperCommand(ctx, addr, "ruok")
+		if err != nil {
+			return err
+		}
+		if strings.TrimSpace(reply) != "imok" {
+			return fmt.Errorf("ruok: %s", strings.TrimSpace(reply))
+		}
+		reply, err = zooKeeperCommand(ctx, addr, "srvr")
+		if err != nil {
+			return err
+		}
+		scanner := bufio.NewScanner(strings.NewReader(reply))
+		for scanner.Scan() {
+			if mode := strings.TrimPrefix(scanner.Text(), "Mode: "); mode != scanner.Text() {
+				if mode == "read-only" {
+					return fmt.Errorf("node is read-only")
+				}
+				return nil
+			}
+		}
+		return fmt.Errorf("srvr: %s", strings.TrimSpace(reply))
+	}
+}
+
+// zooKeeperCommand sends a four letter word and returns the reply, which
+// ends when the node closes the connection.
+func zooKeeperCommand(ctx context.Context, addr, command string) (string, error) {
+	conn, err := dialTCP(ctx, addr, nil)
+	if err != nil {
+		return "", err
+	}
+	defer conn.Close()
+	if _, err := io.WriteString(conn, command); err != nil {
+		return "", err
+	}
+	reply, err := io.ReadAll(io.LimitReader(conn, 64<<10))
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", command, err)
+	}
+	return string(reply), nil
+}